@@ -0,0 +1,401 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tadilbek11kz/ls-clone/listing"
+)
+
+const (
+	noArgument = iota
+	requiredArgument
+	optionalArgument
+)
+
+type Flag struct {
+	short  rune
+	long   string
+	hasArg int
+	apply  func(o *Options, value string) error
+}
+
+// UsageError is reported with "Try 'ls --help'" and exit status 2.
+type UsageError struct {
+	message string
+}
+
+func (e *UsageError) Error() string {
+	return e.message
+}
+
+var flags = []Flag{
+	{'1', "", noArgument, func(o *Options, _ string) error {
+		// -1 has no effect after -l, as in GNU ls
+		if !o.Long {
+			SetLayout(o, "single-column")
+		}
+		return nil
+	}},
+	{'C', "", noArgument, func(o *Options, _ string) error { SetLayout(o, "vertical"); return nil }},
+	{'x', "", noArgument, func(o *Options, _ string) error { SetLayout(o, "across"); return nil }},
+	{'m', "", noArgument, func(o *Options, _ string) error { SetLayout(o, "commas"); return nil }},
+	{'a', "all", noArgument, func(o *Options, _ string) error { o.All = true; return nil }},
+	{'d', "directory", noArgument, func(o *Options, _ string) error { o.dir = true; return nil }},
+	{'F', "", noArgument, func(o *Options, _ string) error { o.IndicatorStyle = "classify"; return nil }},
+	{0, "classify", optionalArgument, func(o *Options, value string) error {
+		switch value {
+		case "", "always", "yes", "force":
+			o.IndicatorStyle = "classify"
+		case "auto", "tty", "if-tty":
+			if IsTerminal(os.Stdout.Fd()) {
+				o.IndicatorStyle = "classify"
+			}
+		case "never", "no", "none":
+			o.IndicatorStyle = "none"
+		default:
+			return InvalidArgument(value, "--classify", []string{"always", "auto", "never"})
+		}
+		return nil
+	}},
+	{'p', "", noArgument, func(o *Options, _ string) error { o.IndicatorStyle = "slash"; return nil }},
+	{0, "file-type", noArgument, func(o *Options, _ string) error { o.IndicatorStyle = "file-type"; return nil }},
+	{0, "indicator-style", requiredArgument, func(o *Options, value string) error {
+		switch value {
+		case "none", "slash", "file-type", "classify":
+			o.IndicatorStyle = value
+			return nil
+		}
+		return InvalidArgument(value, "--indicator-style", []string{"none", "slash", "file-type", "classify"})
+	}},
+	{'b', "escape", noArgument, func(o *Options, _ string) error { o.QuotingStyle = "escape"; return nil }},
+	{'N', "literal", noArgument, func(o *Options, _ string) error { o.QuotingStyle = "literal"; return nil }},
+	{'Q', "quote-name", noArgument, func(o *Options, _ string) error { o.QuotingStyle = "c"; return nil }},
+	{'q', "hide-control-chars", noArgument, func(o *Options, _ string) error {
+		o.HideControl = true
+		o.hideControlSet = true
+		return nil
+	}},
+	{0, "show-control-chars", noArgument, func(o *Options, _ string) error {
+		o.HideControl = false
+		o.hideControlSet = true
+		return nil
+	}},
+	{0, "quoting-style", requiredArgument, func(o *Options, value string) error {
+		if !listing.IsQuotingStyle(value) {
+			return InvalidArgument(value, "--quoting-style", listing.QuotingStyles())
+		}
+		o.QuotingStyle = value
+		return nil
+	}},
+	{'h', "human-readable", noArgument, func(o *Options, _ string) error { o.Human = true; return nil }},
+	{'i', "inode", noArgument, func(o *Options, _ string) error { o.Inode = true; return nil }},
+	{'s', "size", noArgument, func(o *Options, _ string) error { o.Blocks = true; return nil }},
+	{'l', "", noArgument, func(o *Options, _ string) error { SetLayout(o, "long"); return nil }},
+	{'n', "numeric-uid-gid", noArgument, func(o *Options, _ string) error { SetLayout(o, "long"); o.Numeric = true; return nil }},
+	{'g', "", noArgument, func(o *Options, _ string) error { SetLayout(o, "long"); o.NoOwner = true; return nil }},
+	{'o', "", noArgument, func(o *Options, _ string) error { SetLayout(o, "long"); o.NoGroup = true; return nil }},
+	{'G', "no-group", noArgument, func(o *Options, _ string) error { o.NoGroup = true; return nil }},
+	{0, "author", noArgument, func(o *Options, _ string) error { o.Author = true; return nil }},
+	{0, "full-time", noArgument, func(o *Options, _ string) error {
+		SetLayout(o, "long")
+		o.timeStyle = "full-iso"
+		return nil
+	}},
+	{0, "time-style", requiredArgument, func(o *Options, value string) error {
+		if _, _, err := ParseTimeStyle(value); err != nil {
+			return err
+		}
+		o.timeStyle = value
+		return nil
+	}},
+	{'r', "reverse", noArgument, func(o *Options, _ string) error { o.SortReverse = true; return nil }},
+	{'t', "", noArgument, func(o *Options, _ string) error { o.SortTime = true; return nil }},
+	{'u', "", noArgument, func(o *Options, _ string) error {
+		o.TimeField = "atime"
+		o.timeShortFlag = true
+		return nil
+	}},
+	{'c', "", noArgument, func(o *Options, _ string) error {
+		o.TimeField = "ctime"
+		o.timeShortFlag = true
+		return nil
+	}},
+	{0, "time", requiredArgument, func(o *Options, value string) error {
+		field, err := ParseTimeField(value)
+		if err != nil {
+			return err
+		}
+		o.TimeField = field
+		return nil
+	}},
+	{'S', "", noArgument, func(o *Options, _ string) error { o.SortSize = true; return nil }},
+	{'X', "", noArgument, func(o *Options, _ string) error { o.SortExtension = true; return nil }},
+	{'v', "", noArgument, func(o *Options, _ string) error { o.SortVersion = true; return nil }},
+	{'U', "", noArgument, func(o *Options, _ string) error { o.Unsorted = true; return nil }},
+	{'f', "", noArgument, func(o *Options, _ string) error {
+		o.All = true
+		o.Unsorted = true
+		o.Long = false
+		o.Blocks = false
+		o.Color = false
+		return nil
+	}},
+	{0, "sort", requiredArgument, func(o *Options, value string) error {
+		keys, none, err := ParseSortKeys(value)
+		if err != nil {
+			return err
+		}
+		o.SortKeys = keys
+		o.Unsorted = none
+		return nil
+	}},
+	{'R', "recursive", noArgument, func(o *Options, _ string) error { o.recursive = true; return nil }},
+	{'w', "width", requiredArgument, func(o *Options, value string) error {
+		width, err := ParseWidth(value)
+		if err != nil {
+			return err
+		}
+		o.Width = width
+		return nil
+	}},
+	{'T', "tabsize", requiredArgument, func(o *Options, value string) error {
+		size, err := listing.ParseTabSize(value)
+		if err != nil {
+			return err
+		}
+		o.TabSize = size
+		o.tabSizeSet = true
+		return nil
+	}},
+	{0, "hyperlink", optionalArgument, func(o *Options, value string) error {
+		switch value {
+		case "", "always", "yes", "force":
+			o.Hyperlink = true
+		case "auto", "tty", "if-tty":
+			o.Hyperlink = IsTerminal(os.Stdout.Fd())
+		case "never", "no", "none":
+			o.Hyperlink = false
+		default:
+			return InvalidArgument(value, "--hyperlink", []string{"always", "auto", "never"})
+		}
+		return nil
+	}},
+	{0, "icons", optionalArgument, func(o *Options, value string) error {
+		switch value {
+		case "", "always", "yes", "force":
+			o.Icons = true
+		case "auto", "tty", "if-tty":
+			o.Icons = IsTerminal(os.Stdout.Fd())
+		case "never", "no", "none":
+			o.Icons = false
+		default:
+			return InvalidArgument(value, "--icons", []string{"always", "auto", "never"})
+		}
+		return nil
+	}},
+	{0, "git", noArgument, func(o *Options, _ string) error { o.Git = true; return nil }},
+	{0, "tree", noArgument, func(o *Options, _ string) error { o.tree = true; return nil }},
+	{'L', "level", requiredArgument, func(o *Options, value string) error {
+		level, err := listing.ParseLevel(value)
+		if err != nil {
+			return err
+		}
+		o.level = level
+		return nil
+	}},
+	{0, "jobs", requiredArgument, func(o *Options, value string) error {
+		jobs, err := listing.ParseJobs(value)
+		if err != nil {
+			return err
+		}
+		o.jobs = jobs
+		return nil
+	}},
+	{0, "dirs-first", noArgument, func(o *Options, _ string) error { o.DirsFirst = true; return nil }},
+	{0, "format", requiredArgument, func(o *Options, value string) error {
+		switch value {
+		case "verbose", "long", "commas", "horizontal", "across", "vertical", "single-column":
+			SetLayout(o, value)
+			return nil
+		case "csv", "tsv":
+			SetLayout(o, "")
+			o.Format = value
+			return nil
+		}
+		return InvalidArgument(value, "--format", formats)
+	}},
+	{0, "json", noArgument, func(o *Options, _ string) error { o.JSON = true; return nil }},
+	{0, "help", noArgument, func(o *Options, _ string) error { o.help = true; return nil }},
+	{0, "nocolor", noArgument, func(o *Options, _ string) error { o.Color = false; return nil }},
+}
+
+var formats = []string{
+	"verbose", "long", "commas", "horizontal", "across", "vertical", "single-column", "csv", "tsv",
+}
+
+// SetLayout selects the output format of -1, -C, -x, -m, -l and --format.
+// The last one given wins.
+func SetLayout(o *Options, layout string) {
+	o.Long = layout == "long" || layout == "verbose"
+	o.One = layout == "single-column"
+	o.Across = layout == "across" || layout == "horizontal"
+	o.Commas = layout == "commas"
+	o.Format = ""
+	o.layoutSet = true
+}
+
+func InvalidArgument(value, option string, valid []string) error {
+	message := fmt.Sprintf("invalid argument '%s' for '%s'\nValid arguments are:", value, option)
+	for _, v := range valid {
+		message += fmt.Sprintf("\n  - '%s'", v)
+	}
+	return &UsageError{message}
+}
+
+var sortKeyNames = []string{
+	"name", "size", "time", "mtime", "atime", "ctime", "ext", "extension",
+	"version", "owner", "group", "inode", "nlink", "type", "none",
+}
+
+// ParseSortKeys reads a --sort specification such as "type,-size,name".
+// Every key sorts in ascending order, a leading "-" reverses it, and the
+// following keys break ties, so "type,-size,name" lists directories, then
+// the largest files. "none" on its own keeps directory order.
+func ParseSortKeys(value string) ([]listing.SortKey, bool, error) {
+	if value == "none" {
+		return nil, true, nil
+	}
+	var keys []listing.SortKey
+	for _, name := range strings.Split(value, ",") {
+		key, ok := listing.NewSortKey(strings.TrimPrefix(name, "-"), strings.HasPrefix(name, "-"))
+		if !ok {
+			return nil, false, InvalidArgument(name, "--sort", sortKeyNames)
+		}
+		keys = append(keys, key)
+	}
+	return keys, false, nil
+}
+
+func FindShortFlag(c rune) *Flag {
+	for i := range flags {
+		if flags[i].short == c {
+			return &flags[i]
+		}
+	}
+	return nil
+}
+
+// FindLongFlag resolves a long option name, accepting any unambiguous
+// prefix the way getopt_long does. An exact match always wins.
+func FindLongFlag(name string) (*Flag, error) {
+	var matches []*Flag
+	for i := range flags {
+		if flags[i].long == "" {
+			continue
+		}
+		if flags[i].long == name {
+			return &flags[i], nil
+		}
+		if strings.HasPrefix(flags[i].long, name) {
+			matches = append(matches, &flags[i])
+		}
+	}
+	if len(matches) == 0 {
+		return nil, &UsageError{fmt.Sprintf("unrecognized option '--%s'", name)}
+	}
+	if len(matches) > 1 {
+		possibilities := ""
+		for _, m := range matches {
+			possibilities += fmt.Sprintf(" '--%s'", m.long)
+		}
+		return nil, &UsageError{fmt.Sprintf("option '--%s' is ambiguous; possibilities:%s", name, possibilities)}
+	}
+	return matches[0], nil
+}
+
+// ParseOptions splits the command line into options and operands. Options
+// may appear anywhere unless POSIXLY_CORRECT is set, short options can be
+// grouped, long options take "=value" or the next argument, and "--" ends
+// option parsing.
+func ParseOptions(args []string) (Options, []string, error) {
+	options := Options{}
+	options.Color = true
+	var files []string
+	posix := os.Getenv("POSIXLY_CORRECT") != ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			files = append(files, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			if posix {
+				files = append(files, args[i:]...)
+				break
+			}
+			files = append(files, arg)
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			name := arg[2:]
+			value := ""
+			hasValue := false
+			if index := strings.Index(name, "="); index >= 0 {
+				name, value, hasValue = name[:index], name[index+1:], true
+			}
+			flag, err := FindLongFlag(name)
+			if err != nil {
+				return options, nil, err
+			}
+			switch flag.hasArg {
+			case noArgument:
+				if hasValue {
+					return options, nil, &UsageError{fmt.Sprintf("option '--%s' doesn't allow an argument", flag.long)}
+				}
+			case requiredArgument:
+				if !hasValue {
+					if i+1 >= len(args) {
+						return options, nil, &UsageError{fmt.Sprintf("option '--%s' requires an argument", flag.long)}
+					}
+					i++
+					value = args[i]
+				}
+			}
+			if err := flag.apply(&options, value); err != nil {
+				return options, nil, err
+			}
+			continue
+		}
+
+		cluster := []rune(arg[1:])
+		for j := 0; j < len(cluster); j++ {
+			flag := FindShortFlag(cluster[j])
+			if flag == nil {
+				return options, nil, &UsageError{fmt.Sprintf("invalid option -- '%c'", cluster[j])}
+			}
+			value := ""
+			if flag.hasArg == optionalArgument && j+1 < len(cluster) {
+				value = string(cluster[j+1:])
+				j = len(cluster)
+			} else if flag.hasArg == requiredArgument {
+				if j+1 < len(cluster) {
+					value = string(cluster[j+1:])
+				} else if i+1 < len(args) {
+					i++
+					value = args[i]
+				} else {
+					return options, nil, &UsageError{fmt.Sprintf("option requires an argument -- '%c'", cluster[j])}
+				}
+				j = len(cluster)
+			}
+			if err := flag.apply(&options, value); err != nil {
+				return options, nil, err
+			}
+		}
+	}
+	return options, files, nil
+}
@@ -0,0 +1,249 @@
+package listing
+
+// Names are collated with one table for Latin, Cyrillic and Greek, an
+// approximation of the Unicode collation algorithm, which the language of
+// LC_COLLATE tailors: Swedish puts å, ä and ö after z, Kazakh і after ы,
+// and so on. Contractions such as the Czech ch are not applied.
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Letters that sort as a Latin base letter with an accent. The position in
+// the string is the secondary weight, so accented forms follow the plain
+// letter and stay in a fixed order among themselves.
+var latinAccents = map[rune]string{
+	'a': "àáâãäåāăąǎǻạảấầẩẫậắằẳẵặ",
+	'c': "çćĉċč",
+	'd': "ďđð",
+	'e': "èéêëēĕėęěẹẻẽếềểễệ",
+	'g': "ĝğġģǧ",
+	'h': "ĥħ",
+	'i': "ìíîïĩīĭįıǐỉị",
+	'j': "ĵ",
+	'k': "ķǩ",
+	'l': "ĺļľŀł",
+	'n': "ñńņňŉ",
+	'o': "òóôõöøōŏőǒọỏốồổỗộớờởỡợơ",
+	'r': "ŕŗř",
+	's': "śŝşšș",
+	't': "ţťŧț",
+	'u': "ùúûüũūŭůűųưǔụủứừửữự",
+	'w': "ŵ",
+	'y': "ýÿŷỳỵỷỹ",
+	'z': "źżž",
+}
+
+// Letters that expand to more than one base letter.
+var latinExpansions = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+}
+
+// Cyrillic letters in alphabetical order, covering Russian, Kazakh and the
+// other common alphabets. Variants with a diacritic are in cyrillicAccents.
+const cyrillicOrder = "аәбвгғґдђеєжзѕиійјкқлљмнңњоөпрстћуүұфхһцчџшщъыьэюя"
+
+var cyrillicAccents = map[rune]string{
+	'г': "ѓ",
+	'е': "ё",
+	'і': "ї",
+	'к': "ќ",
+	'у': "ў",
+}
+
+// Letters that a language sorts as letters of their own, in the order of
+// each string, after its first letter, instead of as accented forms.
+var languageLetters = map[string][]string{
+	"cs": {"cč", "rř", "sš", "zž"},
+	"da": {"zæøå"},
+	"es": {"nñ"},
+	"fi": {"zåäö"},
+	"kk": {"её", "уұү", "ыі"},
+	"nb": {"zæøå"},
+	"nn": {"zæøå"},
+	"no": {"zæøå"},
+	"pl": {"aą", "cć", "eę", "lł", "nń", "oó", "sś", "zźż"},
+	"sk": {"aä", "cč", "oô", "sš", "zž"},
+	"sv": {"zåäö"},
+	"tr": {"cç", "gğ", "hı", "oö", "sş", "uü"},
+}
+
+var greekAccents = map[rune]string{
+	'α': "ά",
+	'ε': "έ",
+	'η': "ή",
+	'ι': "ίϊΐ",
+	'ο': "ό",
+	'σ': "ς",
+	'υ': "ύϋΰ",
+	'ω': "ώ",
+}
+
+type collationElement struct {
+	primary   int
+	secondary byte
+	tertiary  byte
+}
+
+// primaryStep leaves room between two letters of a table for the letters
+// that a language puts after the first one.
+const primaryStep = 16
+
+var collationElements map[rune][]collationElement
+
+// tailorings holds the weights that each language of languageLetters
+// changes.
+var tailorings map[string]map[rune][]collationElement
+
+func init() {
+	collationElements = make(map[rune][]collationElement)
+	add := func(r rune, primary int, secondary byte) {
+		collationElements[r] = []collationElement{{primary, secondary, 1}}
+	}
+	for r := 'a'; r <= 'z'; r++ {
+		add(r, 0x200+primaryStep*int(r-'a'), 1)
+	}
+	add('þ', 0x200+primaryStep*26, 1)
+	for base, accents := range latinAccents {
+		for i, r := range []rune(accents) {
+			add(r, collationElements[base][0].primary, byte(i+2))
+		}
+	}
+	for r, expansion := range latinExpansions {
+		var elements []collationElement
+		for _, e := range expansion {
+			element := collationElements[e][0]
+			element.secondary = 2
+			elements = append(elements, element)
+		}
+		collationElements[r] = elements
+	}
+	for i, r := range []rune(cyrillicOrder) {
+		add(r, 0x20000+primaryStep*i, 1)
+	}
+	for base, accents := range cyrillicAccents {
+		for i, r := range []rune(accents) {
+			add(r, collationElements[base][0].primary, byte(i+2))
+		}
+	}
+	for r := 'α'; r <= 'ω'; r++ {
+		if r != 'ς' {
+			add(r, 0x10000+int(r), 1)
+		}
+	}
+	for base, accents := range greekAccents {
+		for i, r := range []rune(accents) {
+			add(r, collationElements[base][0].primary, byte(i+2))
+		}
+	}
+
+	tailorings = make(map[string]map[rune][]collationElement)
+	for language, rules := range languageLetters {
+		tailoring := make(map[rune][]collationElement)
+		for _, rule := range rules {
+			letters := []rune(rule)
+			base := collationElements[letters[0]][0].primary
+			for i, r := range letters[1:] {
+				tailoring[r] = []collationElement{{base + i + 1, 1, 1}}
+			}
+		}
+		tailorings[language] = tailoring
+	}
+}
+
+// GetCollationElements returns the weights of a rune. Punctuation, symbols
+// and spaces are ignored until the final byte comparison, digits come
+// before letters and scripts without a table sort by code point. A zero
+// weight means the rune has nothing at that level.
+func GetCollationElements(r rune) []collationElement {
+	return tailoredElements(r, nil)
+}
+
+// tailoredElements returns the weights of a rune, taken from tailoring
+// when it has the rune.
+func tailoredElements(r rune, tailoring map[rune][]collationElement) []collationElement {
+	tertiary := byte(1)
+	lower := unicode.ToLower(r)
+	if lower != r {
+		tertiary = 2
+	}
+	elements, ok := tailoring[lower]
+	if !ok {
+		elements, ok = collationElements[lower]
+	}
+	if ok {
+		if tertiary == 1 {
+			return elements
+		}
+		upper := make([]collationElement, len(elements))
+		for i, e := range elements {
+			e.tertiary = tertiary
+			upper[i] = e
+		}
+		return upper
+	}
+	switch {
+	case r >= '0' && r <= '9':
+		return []collationElement{{0x100 + int(r-'0'), 1, 1}}
+	case unicode.Is(unicode.Mn, r):
+		// combining marks only change the accent level
+		return []collationElement{{0, 0x80 | byte(r&0x7f), 0}}
+	case unicode.IsLetter(lower) || unicode.IsDigit(r):
+		return []collationElement{{0x100000 + int(lower), 1, tertiary}}
+	}
+	return nil
+}
+
+// CollationKey turns a name into a string whose byte order is the
+// approximate Unicode collation order: primary weights (letters), then accents, then case,
+// then the raw name so that different names never compare equal.
+// Invalid UTF-8 bytes are ignored until the last level.
+func CollationKey(name string) string {
+	return tailoredKey(name, nil)
+}
+
+// localeLanguage returns the language of a locale name, "sv" for
+// "sv_SE.UTF-8@euro".
+func localeLanguage(locale string) string {
+	if i := strings.IndexAny(locale, "_.@"); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.ToLower(locale)
+}
+
+func tailoredKey(name string, tailoring map[rune][]collationElement) string {
+	var primary, secondary, tertiary []byte
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		i += size
+		if r == utf8.RuneError && size == 1 {
+			continue
+		}
+		for _, e := range tailoredElements(r, tailoring) {
+			if e.primary != 0 {
+				primary = append(primary, byte(e.primary>>16), byte(e.primary>>8), byte(e.primary))
+			}
+			if e.secondary != 0 {
+				secondary = append(secondary, e.secondary)
+			}
+			if e.tertiary != 0 {
+				tertiary = append(tertiary, e.tertiary)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(primary) + len(secondary) + len(tertiary) + len(name) + 5)
+	b.Write(primary)
+	b.Write([]byte{0, 0, 0})
+	b.Write(secondary)
+	b.WriteByte(0)
+	b.Write(tertiary)
+	b.WriteByte(0)
+	b.WriteString(name)
+	return b.String()
+}
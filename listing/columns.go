@@ -0,0 +1,121 @@
+package listing
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	DefaultTabSize = 8
+	minColumnWidth = 3
+)
+
+// ParseTabSize validates a -T/--tabsize or TABSIZE value. A tab size of 0
+// disables tabs.
+func ParseTabSize(value string) (int, error) {
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid tab size: '%s'", value)
+	}
+	return size, nil
+}
+
+// indent moves the cursor from column "from" to column "to" with tabs
+// where a tab stop is passed and spaces for the rest.
+func (c *Config) indent(from, to int) string {
+	str := ""
+	for from < to {
+		if c.TabSize != 0 && to/c.TabSize > (from+1)/c.TabSize {
+			str += "\t"
+			from += c.TabSize - from%c.TabSize
+		} else {
+			str += " "
+			from++
+		}
+	}
+	return str
+}
+
+// ColumnWidths finds the most columns the names fit in, the way GNU ls
+// does: every candidate column count gets its own column widths, where
+// all but the last column include a 2 space separator, and the widest
+// candidate narrower than lineWidth wins.
+func ColumnWidths(widths []int, lineWidth int, across bool) []int {
+	if line := oneLine(widths, lineWidth); line != nil {
+		return line
+	}
+	maxCols := lineWidth / minColumnWidth
+	if lineWidth%minColumnWidth != 0 {
+		maxCols++
+	}
+	if maxCols > len(widths) {
+		maxCols = len(widths)
+	}
+	if maxCols < 1 {
+		maxCols = 1
+	}
+
+	lineLen := make([]int, maxCols)
+	colWidth := make([][]int, maxCols)
+	valid := make([]bool, maxCols)
+	for i := range colWidth {
+		lineLen[i] = (i + 1) * minColumnWidth
+		colWidth[i] = make([]int, i+1)
+		for j := range colWidth[i] {
+			colWidth[i][j] = minColumnWidth
+		}
+		valid[i] = true
+	}
+
+	for n, width := range widths {
+		for i := 0; i < maxCols; i++ {
+			if !valid[i] {
+				continue
+			}
+			col := n % (i + 1)
+			if !across {
+				col = n / ((len(widths) + i) / (i + 1))
+			}
+			realWidth := width
+			if col != i {
+				realWidth += 2
+			}
+			if colWidth[i][col] < realWidth {
+				lineLen[i] += realWidth - colWidth[i][col]
+				colWidth[i][col] = realWidth
+				valid[i] = lineLen[i] < lineWidth
+			}
+		}
+	}
+
+	cols := maxCols
+	for cols > 1 && !valid[cols-1] {
+		cols--
+	}
+	return colWidth[cols-1]
+}
+
+// oneLine returns the column widths of all the names on one line, or nil
+// when there are none or they do not fit in lineWidth. ColumnWidths would pick the same
+// widths, but an unlimited line would have it try len(widths) candidates
+// of up to len(widths) columns each.
+func oneLine(widths []int, lineWidth int) []int {
+	if len(widths) == 0 {
+		return nil
+	}
+	line := make([]int, len(widths))
+	length := 0
+	for i, width := range widths {
+		if i != len(widths)-1 {
+			width += 2
+		}
+		if width < minColumnWidth {
+			width = minColumnWidth
+		}
+		line[i] = width
+		if length += width; length >= lineWidth {
+			return nil
+		}
+	}
+	return line
+}
@@ -0,0 +1,60 @@
+package listing
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumnWidths(t *testing.T) {
+	for _, test := range []struct {
+		widths    []int
+		lineWidth int
+		across    bool
+		want      []int
+	}{
+		{[]int{2, 1, 4, 1}, 80, false, []int{4, 3, 6, 3}},
+		{[]int{2, 1, 4, 1}, 14, false, []int{4, 6, 3}},
+		{[]int{2, 1, 4, 1}, 14, true, []int{4, 3, 4}},
+		{[]int{10, 10, 10, 10, 10}, 40, false, []int{12, 12, 12, 3}},
+		{[]int{50}, 10, false, []int{50}},
+		{[]int{2, 1, 4, 1}, math.MaxInt32, false, []int{4, 3, 6, 3}},
+		{nil, 80, false, []int{3}},
+	} {
+		if got := ColumnWidths(test.widths, test.lineWidth, test.across); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ColumnWidths(%v, %d, %v) = %v, want %v", test.widths, test.lineWidth, test.across, got, test.want)
+		}
+	}
+}
+
+// An unlimited width puts every name on one line, as GNU ls -w 0 does,
+// without trying a column count per name.
+func TestUnlimitedWidth(t *testing.T) {
+	m := NewMemFS()
+	var want []string
+	for i := 0; i < 20000; i++ {
+		name := fmt.Sprintf("file%05d", i)
+		if err := m.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, name)
+	}
+	config := DefaultConfig()
+	config.Width = math.MaxInt32
+	config.TabSize = 0
+	entries, total, err := NewFSLister(&config, m).ReadDir("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	got := NewFormatter(&config).FormatDir("/", entries, total, false)
+	if got != strings.Join(want, "  ") {
+		t.Errorf("got %.80q..., want the names on one line", got)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("laying out 20000 names took %v", elapsed)
+	}
+}
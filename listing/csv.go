@@ -0,0 +1,54 @@
+package listing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{
+	"directory", "name", "type", "permissions", "links", "owner", "group",
+	"size", "major", "minor", "modified", "target",
+}
+
+func (c *Config) newCSVWriter(b *strings.Builder) *csv.Writer {
+	w := csv.NewWriter(b)
+	if c.Format == "tsv" {
+		w.Comma = '\t'
+	}
+	return w
+}
+
+func (c *Config) writeCSVHeader() string {
+	var b strings.Builder
+	w := c.newCSVWriter(&b)
+	w.Write(csvHeader)
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeListToCSV writes one row per entry with the -l fields. Sizes are
+// always in bytes so the values can be summed in a spreadsheet.
+func (c *Config) writeListToCSV(path string, list []Entry) string {
+	var b strings.Builder
+	w := c.newCSVWriter(&b)
+	for _, l := range list {
+		w.Write([]string{
+			path,
+			l.Name,
+			FileType(l),
+			l.Permissions,
+			l.HardLinks,
+			l.Owner,
+			l.Group,
+			fmt.Sprintf("%d", l.Bytes),
+			l.Major,
+			l.Minor,
+			time.Unix(0, l.MtimeNano).Format(time.RFC3339),
+			l.LinkName,
+		})
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
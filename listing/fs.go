@@ -0,0 +1,131 @@
+package listing
+
+import (
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// FS is a file system a FileLister can list. On top of opening files it
+// stats them with and without following symlinks, reads symlinks, and
+// gives the inode data and owner names that fs.FileInfo leaves out.
+// Errors should be *fs.PathError, as from the os package.
+type FS interface {
+	fs.StatFS
+	fs.ReadLinkFS
+	// Attr returns the inode data of info, which came from this FS.
+	Attr(info fs.FileInfo) (Attr, bool)
+	// BirthTime returns when name was created, without following a
+	// symlink. ok is false when that is not known.
+	BirthTime(name string) (t time.Time, ok bool)
+	// LookupUser and LookupGroup return the names of IDs, if they have one.
+	LookupUser(uid uint32) (string, bool)
+	LookupGroup(gid uint32) (string, bool)
+}
+
+// Attr is the part of stat(2) that fs.FileInfo does not carry.
+type Attr struct {
+	// Mode is st_mode, with the file type and permission bits
+	Mode  uint32
+	Ino   uint64
+	Nlink uint64
+	UID   uint32
+	GID   uint32
+	// Blocks is the allocated size in 512 byte blocks
+	Blocks int64
+	Rdev   uint64
+	Atime  time.Time
+	Ctime  time.Time
+}
+
+// OSFS is the file system of the operating system. Unlike os.DirFS it
+// takes the names of the command line as they are: relative to the
+// working directory, absolute, or with "..".
+type OSFS struct{}
+
+func (OSFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (OSFS) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (OSFS) ReadLink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+func (OSFS) Attr(info fs.FileInfo) (Attr, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return Attr{}, false
+	}
+	return Attr{
+		Mode:   stat.Mode,
+		Ino:    stat.Ino,
+		Nlink:  uint64(stat.Nlink),
+		UID:    stat.Uid,
+		GID:    stat.Gid,
+		Blocks: stat.Blocks,
+		Rdev:   uint64(stat.Rdev),
+		Atime:  time.Unix(stat.Atim.Unix()),
+		Ctime:  time.Unix(stat.Ctim.Unix()),
+	}, true
+}
+
+func (OSFS) BirthTime(name string) (time.Time, bool) {
+	return GetBirthTime(name)
+}
+
+func (OSFS) LookupUser(uid uint32) (string, bool) {
+	u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+	if err != nil {
+		return "", false
+	}
+	return u.Username, true
+}
+
+func (OSFS) LookupGroup(gid uint32) (string, bool) {
+	g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10))
+	if err != nil {
+		return "", false
+	}
+	return g.Name, true
+}
+
+// readDir returns the entries of the directory name in directory order,
+// lstat'ed.
+func readDir(fsys FS, name string) ([]fs.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dir, ok := f.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdirent", Path: name, Err: syscall.ENOTDIR}
+	}
+	entries, err := dir.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]fs.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if os.IsNotExist(err) {
+			// removed since the directory was read
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
@@ -0,0 +1,89 @@
+package listing
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// the Linux limit on symlinks followed in a path
+const maxSymlinks = 40
+
+// isUnreserved reports whether c can be written as is in a URL path, per
+// RFC 3986.
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func PercentEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if isUnreserved(s[i]) || keepSlash && s[i] == '/' {
+			b.WriteByte(s[i])
+		} else {
+			fmt.Fprintf(&b, "%%%02x", s[i])
+		}
+	}
+	return b.String()
+}
+
+// URL returns the file:// URL of path, with symlinks resolved like GNU ls
+// does. Dangling links point at their missing target.
+func (fl *FileLister) URL(path string) string {
+	return "file://" + PercentEncode(fl.config.Hostname, false) + PercentEncode(fl.realPath(path), true)
+}
+
+// realPath makes name absolute and resolves the symlinks in it through the
+// FS. From the first element that cannot be looked up, the rest of name is
+// kept as it is.
+func (fl *FileLister) realPath(name string) string {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(fl.wd, name)
+	}
+	resolved := "/"
+	todo := strings.Split(name, "/")
+	for links := 0; len(todo) > 0; {
+		elem := todo[0]
+		todo = todo[1:]
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+		next := filepath.Join(resolved, elem)
+		info, err := fl.fs.Lstat(next)
+		if err != nil {
+			return filepath.Join(append([]string{next}, todo...)...)
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		link, err := fl.fs.ReadLink(next)
+		if links++; err != nil || links > maxSymlinks {
+			return filepath.Join(append([]string{next}, todo...)...)
+		}
+		if filepath.IsAbs(link) {
+			resolved = "/"
+		}
+		todo = append(strings.Split(link, "/"), todo...)
+	}
+	return resolved
+}
+
+// hyperlink wraps a quoted name in an OSC 8 escape sequence pointing at
+// url. The sequences take no room on the terminal. With skipQuotes the
+// enclosing quotes stay outside the link so that aligned links start in
+// the same column, as in GNU ls.
+func (c *Config) hyperlink(name, url string, skipQuotes bool) string {
+	before, after := "", ""
+	if skipQuotes && len(name) > 1 && name[len(name)-1] == name[0] {
+		before, after = name[:1], name[len(name)-1:]
+		name = name[1 : len(name)-1]
+	}
+	return before + "\033]8;;" + url + "\a" + name + "\033]8;;\a" + after
+}
@@ -0,0 +1,217 @@
+package listing
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Nerd Font glyphs by file name, then by "*.ext" for files.
+var iconsByName = map[string]string{
+	".git":               "\ue5fb",
+	".github":            "\ue5fd",
+	".gitignore":         "\uf1d3",
+	".gitattributes":     "\uf1d3",
+	".gitmodules":        "\uf1d3",
+	".bashrc":            "\uf489",
+	".profile":           "\uf489",
+	".zshrc":             "\uf489",
+	"go.mod":             "\ue627",
+	"go.sum":             "\ue627",
+	"Dockerfile":         "\uf308",
+	"docker-compose.yml": "\uf308",
+	"Makefile":           "\ue779",
+	"makefile":           "\ue779",
+	"GNUmakefile":        "\ue779",
+	"LICENSE":            "\uf24e",
+	"node_modules":       "\ue5fa",
+	"*.go":               "\ue627",
+	"*.c":                "\ue61e",
+	"*.h":                "\uf0fd",
+	"*.cpp":              "\ue61d",
+	"*.rs":               "\ue7a8",
+	"*.py":               "\ue606",
+	"*.rb":               "\ue21e",
+	"*.js":               "\ue74e",
+	"*.ts":               "\ue628",
+	"*.java":             "\ue256",
+	"*.php":              "\ue73d",
+	"*.lua":              "\ue620",
+	"*.vim":              "\ue62b",
+	"*.sh":               "\uf489",
+	"*.bash":             "\uf489",
+	"*.zsh":              "\uf489",
+	"*.html":             "\uf13b",
+	"*.css":              "\ue749",
+	"*.md":               "\uf48a",
+	"*.txt":              "\uf15c",
+	"*.json":             "\ue60b",
+	"*.xml":              "\ue619",
+	"*.yml":              "\ue615",
+	"*.yaml":             "\ue615",
+	"*.toml":             "\ue615",
+	"*.ini":              "\ue615",
+	"*.conf":             "\ue615",
+	"*.csv":              "\uf1c3",
+	"*.sql":              "\uf1c0",
+	"*.db":               "\uf1c0",
+	"*.lock":             "\uf023",
+	"*.diff":             "\uf440",
+	"*.patch":            "\uf440",
+	"*.pdf":              "\uf1c1",
+	"*.zip":              "\uf410",
+	"*.tar":              "\uf410",
+	"*.gz":               "\uf410",
+	"*.xz":               "\uf410",
+	"*.bz2":              "\uf410",
+	"*.7z":               "\uf410",
+	"*.rar":              "\uf410",
+	"*.png":              "\uf1c5",
+	"*.jpg":              "\uf1c5",
+	"*.jpeg":             "\uf1c5",
+	"*.gif":              "\uf1c5",
+	"*.svg":              "\uf1c5",
+	"*.webp":             "\uf1c5",
+	"*.ico":              "\uf1c5",
+	"*.mp3":              "\uf001",
+	"*.flac":             "\uf001",
+	"*.wav":              "\uf001",
+	"*.ogg":              "\uf001",
+	"*.mp4":              "\uf03d",
+	"*.mkv":              "\uf03d",
+	"*.webm":             "\uf03d",
+	"*.mov":              "\uf03d",
+}
+
+// Glyphs by ColorType, used when the name has no icon of its own.
+var iconsByType = map[string]string{
+	"":                     "\uf15b",
+	"directory":            "\ue5ff",
+	"directory_sticky":     "\ue5ff",
+	"directory_o+w":        "\ue5ff",
+	"directory_o+w_sticky": "\ue5ff",
+	"symlink":              "\uf481",
+	"link_orphan":          "\uf127",
+	"executable":           "\uf489",
+	"executable_suid":      "\uf489",
+	"executable_sgid":      "\uf489",
+	"socket":               "\uf1e6",
+	"pipe":                 "\U000f07e5",
+	"block":                "\U000f02ca",
+	"character":            "\U000f030c",
+}
+
+// LS_COLORS codes accepted in the icons file, by ColorType.
+var iconTypeCodes = map[string]string{
+	"fi": "",
+	"di": "directory",
+	"st": "directory_sticky",
+	"ow": "directory_o+w",
+	"tw": "directory_o+w_sticky",
+	"ln": "symlink",
+	"or": "link_orphan",
+	"ex": "executable",
+	"su": "executable_suid",
+	"sg": "executable_sgid",
+	"so": "socket",
+	"pi": "pipe",
+	"bd": "block",
+	"cd": "character",
+}
+
+// IconsFile is $XDG_CONFIG_HOME/ls/icons, by default ~/.config/ls/icons.
+func IconsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ls", "icons")
+}
+
+// LoadIcons reads the icons file into Config.UserIcons. Each line is
+// KEY=GLYPH where KEY is a file name, "*.ext" or one of the LS_COLORS type
+// codes, and GLYPH may use \u escapes. The lines that are not are skipped
+// and returned as warnings. A missing file is not an error.
+func LoadIcons(path string) (icons map[string]string, warnings []error, err error) {
+	icons = make(map[string]string)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return icons, nil, nil
+	} else if err != nil {
+		return icons, nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			warnings = append(warnings, fmt.Errorf("%s:%d: ignoring line without KEY=GLYPH", path, n))
+			continue
+		}
+		key, glyph := line[:i], line[i+1:]
+		if unquoted, err := strconv.Unquote(`"` + glyph + `"`); err == nil {
+			glyph = unquoted
+		}
+		if strings.HasPrefix(key, "*.") {
+			key = strings.ToLower(key)
+		}
+		icons[key] = glyph
+	}
+	return icons, warnings, scanner.Err()
+}
+
+// getIcon picks the glyph for an entry: by name, then by extension for
+// anything but directories, then by type. At each step the icons of the
+// user win over the defaults.
+func (c *Config) getIcon(l Entry) string {
+	if _, isCode := iconTypeCodes[l.Name]; !isCode {
+		if icon, ok := c.UserIcons[l.Name]; ok {
+			return icon
+		}
+	}
+	if icon, ok := iconsByName[l.Name]; ok {
+		return icon
+	}
+	colorType := ColorType(l)
+	if !strings.HasPrefix(colorType, "directory") {
+		if i := strings.LastIndex(l.Name, "."); i > 0 {
+			ext := "*" + strings.ToLower(l.Name[i:])
+			if icon, ok := c.UserIcons[ext]; ok {
+				return icon
+			}
+			if icon, ok := iconsByName[ext]; ok {
+				return icon
+			}
+		}
+	}
+	for code, t := range iconTypeCodes {
+		if t == colorType {
+			if icon, ok := c.UserIcons[code]; ok {
+				return icon
+			}
+		}
+	}
+	return iconsByType[colorType]
+}
+
+// writeIcon returns the icon and the space that separates it from the
+// name, or "" without --icons.
+func (c *Config) writeIcon(l Entry) string {
+	if !c.Icons {
+		return ""
+	}
+	return c.getIcon(l) + " "
+}
+
+// iconWidth is the number of terminal cells writeIcon takes up.
+func (c *Config) iconWidth(l Entry) int {
+	return DisplayWidth(c.writeIcon(l))
+}
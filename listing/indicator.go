@@ -0,0 +1,46 @@
+package listing
+
+import "os"
+
+// modeIndicator returns the character -F, -p or --indicator-style append
+// after a name of the given type, or "" when the style has none for it.
+func (c *Config) modeIndicator(mode os.FileMode) string {
+	style := c.IndicatorStyle
+	if style == "" || style == "none" {
+		return ""
+	}
+	switch {
+	case mode.IsDir():
+		return "/"
+	case style == "slash":
+		return ""
+	case mode&os.ModeSymlink != 0:
+		return "@"
+	case mode&os.ModeNamedPipe != 0:
+		return "|"
+	case mode&os.ModeSocket != 0:
+		return "="
+	case style == "classify" && mode.IsRegular() && mode&0111 != 0:
+		return "*"
+	}
+	return ""
+}
+
+func (c *Config) indicator(l Entry) string {
+	var mode os.FileMode
+	switch FileType(l) {
+	case "directory":
+		mode = os.ModeDir
+	case "symlink":
+		mode = os.ModeSymlink
+	case "pipe":
+		mode = os.ModeNamedPipe
+	case "socket":
+		mode = os.ModeSocket
+	case "file":
+		mode = os.FileMode(l.Mode & 0111)
+	default:
+		mode = os.ModeDevice
+	}
+	return c.modeIndicator(mode)
+}
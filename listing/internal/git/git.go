@@ -0,0 +1,409 @@
+// Package git reads the status of files in a git work tree straight from
+// the index, the object store and the ignore files of its repository.
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repository is a git work tree whose index, HEAD commit and ignore rules
+// are read directly from the .git directory.
+type Repository struct {
+	root        string
+	gitDir      string
+	commonDir   string
+	index       []IndexEntry
+	indexTime   time.Time
+	head        map[string]treeEntry
+	headPaths   []string
+	packs       []*gitPack
+	excludes    []ignoreRule
+	ignoreFiles map[string][]ignoreRule
+	ignoredDirs map[string]bool
+	err         error
+	// reported is set once Cache.Status has returned err
+	reported bool
+}
+
+// RepositoryError is a work tree whose repository could not be read.
+type RepositoryError struct {
+	Root string
+	Err  error
+}
+
+func (e *RepositoryError) Error() string {
+	return fmt.Sprintf("%s: cannot read git repository: %v", e.Root, e.Err)
+}
+
+// Cache holds the repository of every directory looked up, nil when it is
+// not inside a work tree. Its lock guards the map and the caches of the
+// repositories, which the -R workers share.
+type Cache struct {
+	lock         sync.Mutex
+	repositories map[string]*Repository
+}
+
+func NewCache() *Cache {
+	return &Cache{repositories: make(map[string]*Repository)}
+}
+
+// FindRepository returns the repository whose work tree contains dir.
+func (cache *Cache) FindRepository(dir string) *Repository {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	var visited []string
+	var repo *Repository
+	for {
+		if r, ok := cache.repositories[dir]; ok {
+			repo = r
+			break
+		}
+		visited = append(visited, dir)
+		if gitDir := findGitDir(dir); gitDir != "" {
+			repo = OpenRepository(dir, gitDir)
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for _, d := range visited {
+		cache.repositories[d] = repo
+	}
+	return repo
+}
+
+// findGitDir returns the git directory of a work tree root: dir/.git, or
+// the "gitdir:" that a .git file points to in linked work trees and
+// submodules.
+func findGitDir(dir string) string {
+	gitPath := filepath.Join(dir, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return ""
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitPath)
+		if err != nil || !strings.HasPrefix(string(data), "gitdir: ") {
+			return ""
+		}
+		gitPath = strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir: "))
+		if !filepath.IsAbs(gitPath) {
+			gitPath = filepath.Join(dir, gitPath)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(gitPath, "HEAD")); err != nil {
+		return ""
+	}
+	return gitPath
+}
+
+// OpenRepository reads the index and the HEAD tree of a work tree. Errors
+// are kept in r.err so that the listing can go on without statuses.
+func OpenRepository(root, gitDir string) *Repository {
+	r := &Repository{
+		root:        root,
+		gitDir:      gitDir,
+		commonDir:   gitDir,
+		ignoreFiles: make(map[string][]ignoreRule),
+		ignoredDirs: make(map[string]bool),
+	}
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		r.commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(r.commonDir) {
+			r.commonDir = filepath.Join(gitDir, r.commonDir)
+		}
+	}
+
+	indexPath := filepath.Join(gitDir, "index")
+	if info, err := os.Stat(indexPath); err == nil {
+		r.indexTime = info.ModTime()
+		r.index, r.err = ReadIndex(indexPath)
+	}
+	if r.err == nil {
+		r.head, r.err = r.ReadHeadTree()
+	}
+	for p := range r.head {
+		r.headPaths = append(r.headPaths, p)
+	}
+	sort.Strings(r.headPaths)
+
+	if configDir, err := os.UserConfigDir(); err == nil {
+		r.excludes = ParseIgnoreFile(filepath.Join(configDir, "git", "ignore"), "")
+	}
+	r.excludes = append(r.excludes, ParseIgnoreFile(filepath.Join(r.commonDir, "info", "exclude"), "")...)
+	return r
+}
+
+// status characters from the least to the most important, used to sum up
+// the files of a directory
+const statusRank = "-!?TDAMU"
+
+func combineStatus(a, b byte) byte {
+	if strings.IndexByte(statusRank, b) > strings.IndexByte(statusRank, a) {
+		return b
+	}
+	return a
+}
+
+func (r *Repository) searchIndex(p string) int {
+	return sort.Search(len(r.index), func(i int) bool { return r.index[i].path >= p })
+}
+
+func (r *Repository) isTracked(rel string) bool {
+	i := r.searchIndex(rel)
+	return i < len(r.index) && r.index[i].path == rel
+}
+
+// indexStatus compares the entries of one path, starting at r.index[i],
+// with HEAD and with the work tree. It also returns where the next path
+// starts.
+func (r *Repository) indexStatus(i int) (byte, byte, int) {
+	e := r.index[i]
+	next := i + 1
+	for next < len(r.index) && r.index[next].path == e.path {
+		next++
+	}
+	if e.stage != 0 || next-i > 1 {
+		return 'U', 'U', next
+	}
+
+	staged := byte('-')
+	if h, ok := r.head[e.path]; !ok {
+		staged = 'A'
+	} else if h.mode&0170000 != e.mode&0170000 {
+		staged = 'T'
+	} else if h.mode != e.mode || h.id != e.id {
+		staged = 'M'
+	}
+	return staged, r.worktreeStatus(e), next
+}
+
+func (r *Repository) worktreeStatus(e IndexEntry) byte {
+	filename := filepath.Join(r.root, filepath.FromSlash(e.path))
+	info, err := os.Lstat(filename)
+	if err != nil {
+		return 'D'
+	}
+	if e.skipWorktree {
+		return '-'
+	}
+
+	var content []byte
+	switch e.mode & 0170000 {
+	case 0160000:
+		// submodules are compared by their own repository
+		if !info.IsDir() {
+			return 'T'
+		}
+		return '-'
+	case 0120000:
+		if info.Mode()&os.ModeSymlink == 0 {
+			return 'T'
+		}
+		target, err := os.Readlink(filename)
+		if err != nil {
+			return 'M'
+		}
+		content = []byte(target)
+	default:
+		if !info.Mode().IsRegular() {
+			return 'T'
+		}
+		if (e.mode&0111 != 0) != (info.Mode()&0111 != 0) {
+			return 'M'
+		}
+		mtime := info.ModTime()
+		// a file changed in the same second as the index was written
+		// may look clean by its stat data, so it is hashed anyway
+		if uint32(info.Size()) == e.size &&
+			uint32(mtime.Unix()) == e.mtimeSec &&
+			(e.mtimeNsec == 0 || uint32(mtime.Nanosecond()) == e.mtimeNsec) &&
+			mtime.Before(r.indexTime) {
+			return '-'
+		}
+		if content, err = os.ReadFile(filename); err != nil {
+			return 'M'
+		}
+	}
+	if HashBlob(content) != e.id {
+		return 'M'
+	}
+	return '-'
+}
+
+// ignoreRules returns the patterns of the .gitignore in dir.
+func (r *Repository) ignoreRules(dir string) []ignoreRule {
+	rules, ok := r.ignoreFiles[dir]
+	if !ok {
+		rules = ParseIgnoreFile(filepath.Join(r.root, filepath.FromSlash(dir), ".gitignore"), dir)
+		r.ignoreFiles[dir] = rules
+	}
+	return rules
+}
+
+// matchIgnore applies the ignore rules to rel itself, the last matching
+// pattern winning: the global excludes, then info/exclude, then the
+// .gitignore files from the root down.
+func (r *Repository) matchIgnore(rel string, isDir bool) bool {
+	ignored := false
+	check := func(rules []ignoreRule) {
+		for _, rule := range rules {
+			if rule.match(rel, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	check(r.excludes)
+	dir := ""
+	for _, part := range strings.Split(rel, "/") {
+		check(r.ignoreRules(dir))
+		dir = path.Join(dir, part)
+	}
+	return ignored
+}
+
+// IsIgnored reports whether rel or one of its parent directories is
+// ignored. Files in an ignored directory cannot be included again.
+func (r *Repository) IsIgnored(rel string, isDir bool) bool {
+	if dir := path.Dir(rel); dir != "." {
+		ignored, ok := r.ignoredDirs[dir]
+		if !ok {
+			ignored = r.IsIgnored(dir, true)
+			r.ignoredDirs[dir] = ignored
+		}
+		if ignored {
+			return true
+		}
+	}
+	return r.matchIgnore(rel, isDir)
+}
+
+// hasUntracked reports whether the directory rel, which is not ignored,
+// holds a file that is neither tracked nor ignored.
+func (r *Repository) hasUntracked(rel string) bool {
+	files, err := os.ReadDir(filepath.Join(r.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		child := path.Join(rel, f.Name())
+		if f.Name() == ".git" {
+			continue
+		}
+		if f.IsDir() {
+			if !r.matchIgnore(child, true) && r.hasUntracked(child) {
+				return true
+			}
+		} else if !r.isTracked(child) && !r.matchIgnore(child, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// Status returns the staged and the work tree status of a file as two
+// characters: "-" for unchanged, M modified, A added, D deleted, T type
+// changed and U conflicted, or "??" for untracked and "!!" for ignored
+// files. A directory sums up the files under it. Paths outside the work
+// tree or inside .git have no status.
+func (r *Repository) Status(file string, isDir bool) string {
+	if r.err != nil {
+		return ""
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(r.root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+		return ""
+	}
+
+	if rel != "." {
+		if i := r.searchIndex(rel); i < len(r.index) && r.index[i].path == rel {
+			staged, worktree, _ := r.indexStatus(i)
+			return string([]byte{staged, worktree})
+		}
+	}
+	if !isDir {
+		if _, ok := r.head[rel]; ok {
+			return "D?"
+		}
+		if r.IsIgnored(rel, false) {
+			return "!!"
+		}
+		return "??"
+	}
+
+	prefix := rel + "/"
+	if rel == "." {
+		rel, prefix = "", ""
+	}
+	staged, worktree := byte('-'), byte('-')
+	tracked := false
+	for i := r.searchIndex(prefix); i < len(r.index) && strings.HasPrefix(r.index[i].path, prefix); {
+		var s, w byte
+		s, w, i = r.indexStatus(i)
+		staged = combineStatus(staged, s)
+		worktree = combineStatus(worktree, w)
+		tracked = true
+	}
+	for i := sort.SearchStrings(r.headPaths, prefix); i < len(r.headPaths) && strings.HasPrefix(r.headPaths[i], prefix); i++ {
+		if !r.isTracked(r.headPaths[i]) {
+			staged = combineStatus(staged, 'D')
+			tracked = true
+		}
+	}
+
+	if !tracked {
+		if rel != "" && r.IsIgnored(rel, true) {
+			return "!!"
+		}
+		if r.hasUntracked(rel) {
+			return "??"
+		}
+		return "--"
+	}
+	if worktree == '-' && (rel == "" || !r.IsIgnored(rel, true)) && r.hasUntracked(rel) {
+		worktree = '?'
+	}
+	return string([]byte{staged, worktree})
+}
+
+// Status returns the --git column of a listed file, or "" when it is not
+// in a work tree. Directories are looked up from themselves so that the
+// root of a work tree gets the status of the whole tree. A repository that
+// cannot be read gives a *RepositoryError, once.
+func (cache *Cache) Status(file string, isDir bool) (string, error) {
+	dir := filepath.Dir(file)
+	if isDir {
+		dir = file
+	}
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	repo := cache.FindRepository(dir)
+	if repo == nil {
+		return "", nil
+	}
+	if repo.err != nil && !repo.reported {
+		repo.reported = true
+		return "", &RepositoryError{repo.root, repo.err}
+	}
+	return repo.Status(file, isDir), nil
+}
@@ -0,0 +1,249 @@
+package git
+
+import (
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newRepo creates a work tree in a temporary directory, isolated from the
+// git configuration of the user running the tests.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "config", "user.name", "test")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkIndex compares ReadIndex with git ls-files.
+func checkIndex(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := ReadIndex(filepath.Join(dir, ".git", "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, e := range entries {
+		paths = append(paths, e.path)
+	}
+	want := strings.Split(strings.TrimSuffix(runGit(t, dir, "ls-files", "-z"), "\x00"), "\x00")
+	if strings.Join(paths, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ReadIndex paths:\n%s\nwant:\n%s", strings.Join(paths, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// checkHeadTree compares ReadHeadTree with git ls-tree, and the blobs it
+// points to with git cat-file.
+func checkHeadTree(t *testing.T, dir string) {
+	t.Helper()
+	r := OpenRepository(dir, filepath.Join(dir, ".git"))
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	var got []string
+	for name, entry := range r.head {
+		got = append(got, entry.id.String()+" "+name)
+		_, content, err := r.ReadObject(entry.id)
+		if err != nil {
+			t.Errorf("ReadObject %s: %v", name, err)
+		} else if want := runGit(t, dir, "cat-file", "blob", entry.id.String()); string(content) != want {
+			t.Errorf("ReadObject %s = %q, want %q", name, content, want)
+		}
+	}
+	sort.Strings(got)
+	var want []string
+	for _, line := range strings.Split(strings.TrimSpace(runGit(t, dir, "ls-tree", "-r", "HEAD")), "\n") {
+		// "mode type id\tname"
+		fields := strings.SplitN(line, "\t", 2)
+		want = append(want, strings.Fields(fields[0])[2]+" "+fields[1])
+	}
+	sort.Strings(want)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ReadHeadTree:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func checkStatus(t *testing.T, dir string, want map[string]string) {
+	t.Helper()
+	cache := NewCache()
+	for name, status := range want {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		info, err := os.Stat(path)
+		isDir := err == nil && info.IsDir()
+		if got, err := cache.Status(path, isDir); err != nil || got != status {
+			t.Errorf("Status(%s) = %q, %v; want %q", name, got, err, status)
+		}
+	}
+}
+
+func TestIndexVersion2(t *testing.T) {
+	dir := newRepo(t)
+	writeFile(t, dir, "a.txt", "a\n")
+	writeFile(t, dir, "sub/b.txt", "b\n")
+	writeFile(t, dir, "sub/deep/c.txt", "c\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "update-index", "--index-version", "2")
+	checkIndex(t, dir)
+
+	runGit(t, dir, "commit", "-q", "-m", "first")
+	writeFile(t, dir, "a.txt", "changed\n")
+	writeFile(t, dir, "new.txt", "new\n")
+	writeFile(t, dir, ".gitignore", "*.log\n")
+	writeFile(t, dir, "debug.log", "log\n")
+	runGit(t, dir, "add", "new.txt")
+	checkStatus(t, dir, map[string]string{
+		"a.txt":     "-M",
+		"new.txt":   "A-",
+		"debug.log": "!!",
+		"sub/b.txt": "--",
+		"sub":       "--",
+	})
+}
+
+func TestIndexVersion4(t *testing.T) {
+	dir := newRepo(t)
+	// the path after a long one strips more than 127 bytes, which takes
+	// two bytes of git's varint
+	long := strings.Repeat("x", 200)
+	names := []string{"d/" + long, "d/b", "d/c/" + long, "d/c/e", "f"}
+	for _, name := range names {
+		writeFile(t, dir, name, name+"\n")
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "update-index", "--index-version", "4")
+	index, err := os.ReadFile(filepath.Join(dir, ".git", "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version := binary.BigEndian.Uint32(index[4:]); version != 4 {
+		t.Fatalf("index version %d", version)
+	}
+	checkIndex(t, dir)
+}
+
+func TestLooseObjects(t *testing.T) {
+	dir := newRepo(t)
+	writeFile(t, dir, "a.txt", "a\n")
+	writeFile(t, dir, "sub/b.txt", "b\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "first")
+	if packs, _ := filepath.Glob(filepath.Join(dir, ".git", "objects", "pack", "*.pack")); len(packs) != 0 {
+		t.Fatalf("objects are packed: %v", packs)
+	}
+	checkHeadTree(t, dir)
+	checkStatus(t, dir, map[string]string{"a.txt": "--", "sub/b.txt": "--", ".": "--"})
+}
+
+func TestPackedObjects(t *testing.T) {
+	dir := newRepo(t)
+	// versions of a file that git stores as deltas of each other
+	content := strings.Repeat("line of text that stays the same\n", 200)
+	for i, change := range []string{"one\n", "two\n", "three\n"} {
+		writeFile(t, dir, "big.txt", content+change)
+		writeFile(t, dir, "small.txt", change)
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-q", "-m", "commit "+string(rune('0'+i)))
+	}
+	runGit(t, dir, "gc", "-q", "--aggressive")
+	if loose, _ := filepath.Glob(filepath.Join(dir, ".git", "objects", "??", "*")); len(loose) != 0 {
+		t.Fatalf("loose objects left after gc: %v", loose)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", "refs", "heads", "main")); err == nil {
+		t.Fatal("refs are not packed after gc")
+	}
+	checkHeadTree(t, dir)
+	writeFile(t, dir, "small.txt", "four\n")
+	checkStatus(t, dir, map[string]string{"big.txt": "--", "small.txt": "-M"})
+
+	// every version of big.txt, read through the deltas
+	for _, rev := range []string{"HEAD~2", "HEAD~1", "HEAD"} {
+		id, err := ParseObjectID(strings.TrimSpace(runGit(t, dir, "rev-parse", rev+":big.txt")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := OpenRepository(dir, filepath.Join(dir, ".git"))
+		_, data, err := r.ReadObject(id)
+		if err != nil {
+			t.Fatalf("%s: %v", rev, err)
+		}
+		if want := runGit(t, dir, "cat-file", "blob", id.String()); string(data) != want {
+			t.Errorf("%s:big.txt differs from git cat-file", rev)
+		}
+	}
+}
+
+// encodeVarint is encode_varint of git's varint.c.
+func encodeVarint(value uint64) []byte {
+	var buf [16]byte
+	pos := len(buf) - 1
+	buf[pos] = byte(value & 127)
+	for value >>= 7; value != 0; value >>= 7 {
+		value--
+		pos--
+		buf[pos] = 128 | byte(value&127)
+	}
+	return buf[pos:]
+}
+
+func TestDecodeVarint(t *testing.T) {
+	for _, test := range []struct {
+		data  []byte
+		value uint64
+	}{
+		{[]byte{0}, 0},
+		{[]byte{127}, 127},
+		{[]byte{0x80, 0}, 128},
+		{[]byte{0x80, 127}, 255},
+		{[]byte{0x81, 0}, 256},
+		{[]byte{0xff, 127}, 16511},
+		{[]byte{0x80, 0x80, 0}, 16512},
+	} {
+		value, n := decodeVarint(test.data)
+		if value != test.value || n != len(test.data) {
+			t.Errorf("decodeVarint(%x) = %d, %d; want %d, %d", test.data, value, n, test.value, len(test.data))
+		}
+	}
+	for _, value := range []uint64{0, 1, 127, 128, 200, 16383, 16384, 1 << 20, 1<<63 + 12345} {
+		data := encodeVarint(value)
+		if got, n := decodeVarint(data); got != value || n != len(data) {
+			t.Errorf("decodeVarint(encodeVarint(%d)) = %d, %d", value, got, n)
+		}
+	}
+	if _, n := decodeVarint([]byte{0x80}); n != 0 {
+		t.Errorf("truncated varint read %d bytes", n)
+	}
+}
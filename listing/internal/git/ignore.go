@@ -0,0 +1,102 @@
+package git
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+type ignoreRule struct {
+	pattern  []string
+	base     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ParseIgnoreFile reads the patterns of a .gitignore style file. Patterns
+// are relative to base, the directory of the file inside the work tree.
+func ParseIgnoreFile(filename, base string) []ignoreRule {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	var rules []ignoreRule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+			line = line[:len(line)-1]
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var rule ignoreRule
+		rule.base = base
+		if line[0] == '!' {
+			rule.negate = true
+			line = line[1:]
+		} else if line[0] == '\\' {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		// a slash anywhere but at the end ties the pattern to base
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		for _, segment := range strings.Split(line, "/") {
+			rule.pattern = append(rule.pattern, strings.Replace(segment, "[!", "[^", -1))
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matchSegments matches path segments against pattern segments, where
+// "**" stands for any number of directories.
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			// "dir/**" matches what is inside dir, not dir itself
+			return len(parts) > 0
+		}
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// match reports whether the rule applies to rel, a slash separated path
+// from the root of the work tree.
+func (rule ignoreRule) match(rel string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+	if rule.base != "" {
+		if !strings.HasPrefix(rel, rule.base+"/") {
+			return false
+		}
+		rel = rel[len(rule.base)+1:]
+	}
+	if !rule.anchored {
+		return matchSegments(rule.pattern, []string{path.Base(rel)})
+	}
+	return matchSegments(rule.pattern, strings.Split(rel, "/"))
+}
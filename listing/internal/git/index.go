@@ -0,0 +1,116 @@
+package git
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+type IndexEntry struct {
+	path         string
+	mode         uint32
+	id           ObjectID
+	size         uint32
+	mtimeSec     uint32
+	mtimeNsec    uint32
+	stage        int
+	skipWorktree bool
+}
+
+const (
+	indexEntryHeader = 62
+	flagExtended     = 0x4000
+	flagSkipWorktree = 0x4000 // in the extended flags
+)
+
+// ReadIndex parses the entries of a version 2, 3 or 4 git index, which
+// git keeps sorted by path and then by stage.
+func ReadIndex(path string) ([]IndexEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, fmt.Errorf("%s: not a git index", path)
+	}
+	version := binary.BigEndian.Uint32(data[4:])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("%s: unsupported index version %d", path, version)
+	}
+	count := int(binary.BigEndian.Uint32(data[8:]))
+
+	entries := make([]IndexEntry, 0, count)
+	pos := 12
+	previous := ""
+	for i := 0; i < count; i++ {
+		if len(data) < pos+indexEntryHeader {
+			return nil, fmt.Errorf("%s: truncated index", path)
+		}
+		start := pos
+		var e IndexEntry
+		e.mtimeSec = binary.BigEndian.Uint32(data[pos+8:])
+		e.mtimeNsec = binary.BigEndian.Uint32(data[pos+12:])
+		e.mode = binary.BigEndian.Uint32(data[pos+24:])
+		e.size = binary.BigEndian.Uint32(data[pos+36:])
+		copy(e.id[:], data[pos+40:pos+60])
+		flags := binary.BigEndian.Uint16(data[pos+60:])
+		e.stage = int(flags>>12) & 3
+		pos += indexEntryHeader
+		if flags&flagExtended != 0 && version >= 3 {
+			if len(data) < pos+2 {
+				return nil, fmt.Errorf("%s: truncated index", path)
+			}
+			e.skipWorktree = binary.BigEndian.Uint16(data[pos:])&flagSkipWorktree != 0
+			pos += 2
+		}
+
+		if version == 4 {
+			// the path drops a number of bytes from the end of the
+			// previous one and adds a suffix
+			strip, n := decodeVarint(data[pos:])
+			if n <= 0 || int(strip) > len(previous) {
+				return nil, fmt.Errorf("%s: corrupt index", path)
+			}
+			pos += n
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, fmt.Errorf("%s: truncated index", path)
+			}
+			e.path = previous[:len(previous)-int(strip)] + string(data[pos:pos+end])
+			pos += end + 1
+		} else {
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, fmt.Errorf("%s: truncated index", path)
+			}
+			e.path = string(data[pos : pos+end])
+			// entries are padded with NULs to a multiple of 8 bytes
+			pos = start + (pos-start+end+8)&^7
+		}
+		previous = e.path
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// decodeVarint reads a number in the varint of git's varint.c, which is
+// not LEB128: the most significant group of 7 bits comes first, and every
+// continuation adds one so that each value has a single encoding. n is
+// the number of bytes read, or 0 when data is truncated or overflows.
+func decodeVarint(data []byte) (value uint64, n int) {
+	for n < len(data) {
+		c := data[n]
+		n++
+		value |= uint64(c & 127)
+		if c&128 == 0 {
+			return value, n
+		}
+		value++
+		if value == 0 || value>>57 != 0 {
+			return 0, 0
+		}
+		value <<= 7
+	}
+	return 0, 0
+}
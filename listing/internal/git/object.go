@@ -0,0 +1,412 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ObjectID [20]byte
+
+func (id ObjectID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func ParseObjectID(s string) (ObjectID, error) {
+	var id ObjectID
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(id) {
+		return id, fmt.Errorf("invalid object id '%s'", s)
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// HashBlob returns the id git gives to a file with this content.
+func HashBlob(content []byte) ObjectID {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	var id ObjectID
+	copy(id[:], h.Sum(nil))
+	return id
+}
+
+// object types, as numbered in pack files
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var objectTypes = map[string]int{
+	"commit": objCommit,
+	"tree":   objTree,
+	"blob":   objBlob,
+	"tag":    objTag,
+}
+
+var errObjectNotFound = errors.New("object not found")
+
+// gitPack is a pack file with its version 2 index loaded in memory.
+type gitPack struct {
+	file    *os.File
+	fanout  [256]uint32
+	ids     []byte
+	offsets []byte
+	large   []byte
+}
+
+func openPack(idxPath string) (*gitPack, error) {
+	idx, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(idx) < 8+256*4 || !bytes.Equal(idx[:8], []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}) {
+		return nil, fmt.Errorf("%s: unsupported pack index", idxPath)
+	}
+	pack := &gitPack{}
+	for i := range pack.fanout {
+		pack.fanout[i] = binary.BigEndian.Uint32(idx[8+i*4:])
+	}
+	n := int(pack.fanout[255])
+	pos := 8 + 256*4
+	if len(idx) < pos+n*(20+4+4) {
+		return nil, fmt.Errorf("%s: truncated pack index", idxPath)
+	}
+	pack.ids = idx[pos : pos+n*20]
+	pos += n * 24 // ids and CRCs
+	pack.offsets = idx[pos : pos+n*4]
+	pack.large = idx[pos+n*4:]
+
+	pack.file, err = os.Open(strings.TrimSuffix(idxPath, ".idx") + ".pack")
+	if err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// find returns the offset of an object in the pack, or -1.
+func (p *gitPack) find(id ObjectID) int64 {
+	low := 0
+	if id[0] > 0 {
+		low = int(p.fanout[id[0]-1])
+	}
+	high := int(p.fanout[id[0]])
+	i := low + sort.Search(high-low, func(i int) bool {
+		return bytes.Compare(p.ids[(low+i)*20:(low+i+1)*20], id[:]) >= 0
+	})
+	if i >= high || !bytes.Equal(p.ids[i*20:(i+1)*20], id[:]) {
+		return -1
+	}
+	offset := binary.BigEndian.Uint32(p.offsets[i*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset)
+	}
+	i = int(offset &^ 0x80000000)
+	if len(p.large) < (i+1)*8 {
+		return -1
+	}
+	return int64(binary.BigEndian.Uint64(p.large[i*8:]))
+}
+
+// read inflates the object at offset, applying deltas against their base.
+func (p *gitPack) read(r *Repository, offset int64) (int, []byte, error) {
+	br := bufio.NewReader(io.NewSectionReader(p.file, offset, 1<<62))
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	kind := int(c>>4) & 7
+	size := int64(c & 0x0f)
+	for shift := uint(4); c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		size |= int64(c&0x7f) << shift
+	}
+
+	var baseKind int
+	var base []byte
+	switch kind {
+	case objOfsDelta:
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		distance := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			distance = (distance+1)<<7 | int64(c&0x7f)
+		}
+		baseKind, base, err = p.read(r, offset-distance)
+	case objRefDelta:
+		var id ObjectID
+		if _, err = io.ReadFull(br, id[:]); err != nil {
+			return 0, nil, err
+		}
+		baseKind, base, err = r.ReadObject(id)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return 0, nil, err
+	}
+	if kind == objOfsDelta || kind == objRefDelta {
+		data, err = applyDelta(base, data)
+		return baseKind, data, err
+	}
+	return kind, data, nil
+}
+
+var errBadDelta = errors.New("corrupt delta")
+
+func applyDelta(base, delta []byte) ([]byte, error) {
+	pos := 0
+	readSize := func() int {
+		size := 0
+		for shift := uint(0); pos < len(delta); shift += 7 {
+			c := delta[pos]
+			pos++
+			size |= int(c&0x7f) << shift
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		return size
+	}
+	if readSize() != len(base) {
+		return nil, errBadDelta
+	}
+	size := readSize()
+	out := make([]byte, 0, size)
+	for pos < len(delta) {
+		op := delta[pos]
+		pos++
+		if op&0x80 != 0 {
+			offset, n := 0, 0
+			for i := uint(0); i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if pos >= len(delta) {
+					return nil, errBadDelta
+				}
+				if i < 4 {
+					offset |= int(delta[pos]) << (8 * i)
+				} else {
+					n |= int(delta[pos]) << (8 * (i - 4))
+				}
+				pos++
+			}
+			if n == 0 {
+				n = 0x10000
+			}
+			if offset+n > len(base) {
+				return nil, errBadDelta
+			}
+			out = append(out, base[offset:offset+n]...)
+		} else if op != 0 {
+			if pos+int(op) > len(delta) {
+				return nil, errBadDelta
+			}
+			out = append(out, delta[pos:pos+int(op)]...)
+			pos += int(op)
+		} else {
+			return nil, errBadDelta
+		}
+	}
+	if len(out) != size {
+		return nil, errBadDelta
+	}
+	return out, nil
+}
+
+func (r *Repository) loadPacks() {
+	if r.packs != nil {
+		return
+	}
+	r.packs = []*gitPack{}
+	idxPaths, _ := filepath.Glob(filepath.Join(r.commonDir, "objects", "pack", "*.idx"))
+	for _, idxPath := range idxPaths {
+		if pack, err := openPack(idxPath); err == nil {
+			r.packs = append(r.packs, pack)
+		}
+	}
+}
+
+// ReadObject returns the type and content of an object, loose or packed.
+func (r *Repository) ReadObject(id ObjectID) (int, []byte, error) {
+	hexID := id.String()
+	file, err := os.Open(filepath.Join(r.commonDir, "objects", hexID[:2], hexID[2:]))
+	if err == nil {
+		defer file.Close()
+		zr, err := zlib.NewReader(file)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer zr.Close()
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			return 0, nil, err
+		}
+		i := bytes.IndexByte(data, 0)
+		space := bytes.IndexByte(data, ' ')
+		if i < 0 || space < 0 || space > i {
+			return 0, nil, fmt.Errorf("object %s: bad header", hexID)
+		}
+		kind, ok := objectTypes[string(data[:space])]
+		if !ok {
+			return 0, nil, fmt.Errorf("object %s: unknown type", hexID)
+		}
+		return kind, data[i+1:], nil
+	}
+
+	r.loadPacks()
+	for _, pack := range r.packs {
+		if offset := pack.find(id); offset >= 0 {
+			return pack.read(r, offset)
+		}
+	}
+	return 0, nil, errObjectNotFound
+}
+
+type treeEntry struct {
+	mode uint32
+	id   ObjectID
+}
+
+// ReadTree adds the files of a tree and its subtrees to files, by path.
+func (r *Repository) ReadTree(id ObjectID, prefix string, files map[string]treeEntry) error {
+	kind, data, err := r.ReadObject(id)
+	if err != nil {
+		return err
+	}
+	if kind != objTree {
+		return fmt.Errorf("object %s is not a tree", id)
+	}
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+21 {
+			return fmt.Errorf("tree %s: corrupt entry", id)
+		}
+		mode, err := strconv.ParseUint(string(data[:space]), 8, 32)
+		if err != nil {
+			return fmt.Errorf("tree %s: corrupt mode", id)
+		}
+		name := prefix + string(data[space+1:nul])
+		var entry treeEntry
+		entry.mode = uint32(mode)
+		copy(entry.id[:], data[nul+1:nul+21])
+		data = data[nul+21:]
+
+		if entry.mode == 040000 {
+			if err := r.ReadTree(entry.id, name+"/", files); err != nil {
+				return err
+			}
+		} else {
+			files[name] = entry
+		}
+	}
+	return nil
+}
+
+// the most symbolic refs followed, as many as the symlinks of a path
+const maxSymrefs = 40
+
+// ResolveHead returns the commit HEAD points to. ok is false on a branch
+// without commits yet.
+func (r *Repository) ResolveHead() (ObjectID, bool, error) {
+	ref := "HEAD"
+	for i := 0; i < maxSymrefs; i++ {
+		dir := r.commonDir
+		if ref == "HEAD" {
+			dir = r.gitDir
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if os.IsNotExist(err) {
+			id, found, err := r.packedRef(ref)
+			return id, found, err
+		} else if err != nil {
+			return ObjectID{}, false, err
+		}
+		value := strings.TrimSpace(string(data))
+		if !strings.HasPrefix(value, "ref: ") {
+			id, err := ParseObjectID(value)
+			return id, err == nil, err
+		}
+		ref = strings.TrimPrefix(value, "ref: ")
+	}
+	return ObjectID{}, false, errors.New("HEAD: too many levels of symbolic refs")
+}
+
+func (r *Repository) packedRef(ref string) (ObjectID, bool, error) {
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return ObjectID{}, false, nil
+	} else if err != nil {
+		return ObjectID{}, false, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref {
+			id, err := ParseObjectID(fields[0])
+			return id, err == nil, err
+		}
+	}
+	return ObjectID{}, false, nil
+}
+
+// ReadHeadTree returns the files of the commit HEAD points to.
+func (r *Repository) ReadHeadTree() (map[string]treeEntry, error) {
+	files := make(map[string]treeEntry)
+	id, ok, err := r.ResolveHead()
+	if err != nil || !ok {
+		return files, err
+	}
+	// peel tags until the commit
+	for {
+		kind, data, err := r.ReadObject(id)
+		if err != nil {
+			return files, err
+		}
+		if kind == objTree {
+			break
+		}
+		if !bytes.HasPrefix(data, []byte("tree ")) && !bytes.HasPrefix(data, []byte("object ")) {
+			return files, fmt.Errorf("object %s: no tree", id)
+		}
+		line := string(data)
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+		}
+		if id, err = ParseObjectID(line[strings.IndexByte(line, ' ')+1:]); err != nil {
+			return files, err
+		}
+	}
+	return files, r.ReadTree(id, "", files)
+}
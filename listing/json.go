@@ -0,0 +1,84 @@
+package listing
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type JSONEntry struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Inode       uint64  `json:"inode"`
+	Mode        uint32  `json:"mode"`
+	Permissions string  `json:"permissions"`
+	Nlink       uint64  `json:"nlink"`
+	UID         uint32  `json:"uid"`
+	Owner       string  `json:"owner"`
+	GID         uint32  `json:"gid"`
+	Group       string  `json:"group"`
+	Size        int64   `json:"size"`
+	Blocks      int64   `json:"blocks"`
+	Major       *uint64 `json:"major,omitempty"`
+	Minor       *uint64 `json:"minor,omitempty"`
+	LinkTarget  string  `json:"link_target,omitempty"`
+	LinkOrphan  bool    `json:"link_orphan,omitempty"`
+	MtimeNano   int64   `json:"mtime_ns"`
+	AtimeNano   int64   `json:"atime_ns"`
+	CtimeNano   int64   `json:"ctime_ns"`
+	BirthNano   int64   `json:"birth_ns,omitempty"`
+}
+
+// JSONBlock is one NDJSON record: either the file operands given on the
+// command line (no path) or the contents of one directory with its total.
+type JSONBlock struct {
+	Path    string      `json:"path,omitempty"`
+	Total   *int        `json:"total,omitempty"`
+	Entries []JSONEntry `json:"entries"`
+}
+
+func NewJSONEntry(l Entry) JSONEntry {
+	entry := JSONEntry{
+		Name:        l.Name,
+		Type:        FileType(l),
+		Inode:       l.Ino,
+		Mode:        l.Mode,
+		Permissions: l.Permissions,
+		Nlink:       l.Nlink,
+		UID:         l.UID,
+		Owner:       l.Owner,
+		GID:         l.GID,
+		Group:       l.Group,
+		Size:        l.Bytes,
+		Blocks:      l.BlockCount,
+		LinkTarget:  l.LinkName,
+		LinkOrphan:  l.LinkOrphan,
+		MtimeNano:   l.MtimeNano,
+		AtimeNano:   l.AtimeNano,
+		CtimeNano:   l.CtimeNano,
+		BirthNano:   l.BirthNano,
+	}
+	if l.IsBlock || l.IsCharacter {
+		major, _ := strconv.ParseUint(l.Major, 10, 64)
+		minor, _ := strconv.ParseUint(l.Minor, 10, 64)
+		entry.Major = &major
+		entry.Minor = &minor
+	}
+	return entry
+}
+
+// WriteListToJSON renders a block as a single line. A negative total is
+// used for file operands, which have no total line in text output either.
+func WriteListToJSON(path string, total int, list []Entry) string {
+	block := JSONBlock{Path: path, Entries: make([]JSONEntry, 0, len(list))}
+	if total >= 0 {
+		block.Total = &total
+	}
+	for _, l := range list {
+		block.Entries = append(block.Entries, NewJSONEntry(l))
+	}
+	data, err := json.Marshal(block)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,249 @@
+// Package listing reads files and directories and lays them out the way
+// ls does. A Lister turns paths into entries, a Formatter turns entries
+// into text, and Walk and Tree descend into directories. Everything they
+// need is passed in a Config, so listings with different settings can run
+// at the same time. FileLister reads an FS: the operating system's, or a
+// MemFS built in memory.
+package listing
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tadilbek11kz/ls-clone/listing/internal/git"
+)
+
+// Config selects the entries that are listed, their order and their
+// layout. It must not change while it is in use.
+type Config struct {
+	All           bool
+	Long          bool
+	Human         bool
+	One           bool
+	Across        bool
+	Commas        bool
+	Color         bool
+	SortReverse   bool
+	SortTime      bool
+	SortSize      bool
+	SortExtension bool
+	SortVersion   bool
+	Unsorted      bool
+	SortKeys      []SortKey
+	// ByteCollation compares names as bytes, as in the C locale
+	ByteCollation bool
+	// Collation is the locale of LC_COLLATE, such as sv_SE.UTF-8, whose
+	// language tailors the order of names
+	Collation      string
+	IndicatorStyle string
+	Hyperlink      bool
+	Icons          bool
+	Git            bool
+	QuotingStyle   string
+	HideControl    bool
+	DirsFirst      bool
+	JSON           bool
+	// Format is "csv" or "tsv" for records, or "" for text
+	Format           string
+	Inode            bool
+	Blocks           bool
+	Numeric          bool
+	NoOwner          bool
+	NoGroup          bool
+	Author           bool
+	TimeField        string
+	TimeFormatOld    string
+	TimeFormatRecent string
+	// Width is the line width of the columns, math.MaxInt32 for no limit
+	Width   int
+	TabSize int
+	// Colors are the escape sequences of ParseColors
+	Colors map[string]string
+	// UserIcons are the glyphs of LoadIcons, which win over the defaults
+	UserIcons map[string]string
+	// Hostname goes into the file:// URLs of Hyperlink
+	Hostname string
+}
+
+// DefaultConfig is the configuration of a plain ls: names in columns of an
+// 80 cell line, sorted by name, without colors.
+func DefaultConfig() Config {
+	return Config{
+		QuotingStyle:     "literal",
+		TimeFormatOld:    LocaleTimeOld,
+		TimeFormatRecent: LocaleTimeRecent,
+		Width:            80,
+		TabSize:          DefaultTabSize,
+	}
+}
+
+// Entry is one listed file with the fields of every column, formatted as
+// they are shown.
+type Entry struct {
+	Permissions string
+	HardLinks   string
+	Owner       string
+	Group       string
+	Size        string
+	EpochNano   int64
+	Timestamp   string
+	Name        string
+	Path        string
+	// URL and LinkURL are the file:// URLs of Path and LinkName, set for
+	// Hyperlink
+	URL         string
+	LinkURL     string
+	LinkName    string
+	LinkColor   string
+	Major       string
+	Minor       string
+	LinkOrphan  bool
+	LinkMode    os.FileMode
+	IsSocket    bool
+	IsPipe      bool
+	IsBlock     bool
+	IsCharacter bool
+	Mode        uint32
+	Nlink       uint64
+	UID         uint32
+	GID         uint32
+	Bytes       int64
+	MtimeNano   int64
+	AtimeNano   int64
+	CtimeNano   int64
+	BirthNano   int64
+	Ino         uint64
+	Inode       string
+	BlockCount  int64
+	Blocks      string
+	GitStatus   string
+	TreePrefix  string
+}
+
+func (e Entry) IsDir() bool {
+	return e.Permissions[0] == 'd'
+}
+
+// Lister reads entries: Stat the operands, following symlinks, and
+// ReadDir the sorted contents of a directory with its total of 1K blocks.
+type Lister interface {
+	Stat(name string) (Entry, error)
+	ReadDir(name string) ([]Entry, int, error)
+}
+
+// Formatter lays out entries. Header comes before everything else, Format
+// writes the file operands and FormatDir one directory, with a "dir:"
+// line when header is set.
+type Formatter interface {
+	Header() string
+	Format(entries []Entry) string
+	FormatDir(name string, entries []Entry, total int, header bool) string
+}
+
+// FileLister lists the files of an FS. Relative names start from wd. The
+// lock guards the warnings, which the -R workers share.
+type FileLister struct {
+	config   *Config
+	fs       FS
+	git      *git.Cache
+	wd       string
+	lock     sync.Mutex
+	warnings []error
+}
+
+// NewLister lists the files of the operating system.
+func NewLister(config *Config) *FileLister {
+	wd, _ := os.Getwd()
+	return &FileLister{config: config, fs: OSFS{}, git: git.NewCache(), wd: wd}
+}
+
+// NewFSLister lists the files of fsys. The --git column reads repositories
+// from the operating system, so it stays empty.
+func NewFSLister(config *Config, fsys FS) *FileLister {
+	return &FileLister{config: config, fs: fsys, wd: "/"}
+}
+
+// Warnings returns the problems that did not stop a listing, such as a git
+// repository that could not be read, met since the last call.
+func (fl *FileLister) Warnings() []error {
+	fl.lock.Lock()
+	defer fl.lock.Unlock()
+	warnings := fl.warnings
+	fl.warnings = nil
+	return warnings
+}
+
+func (fl *FileLister) warn(err error) {
+	fl.lock.Lock()
+	defer fl.lock.Unlock()
+	fl.warnings = append(fl.warnings, err)
+}
+
+func (fl *FileLister) Stat(name string) (Entry, error) {
+	info, err := fl.fs.Stat(name)
+	if err != nil {
+		return Entry{}, err
+	}
+	dirName := name
+	if !info.IsDir() {
+		splitedPath := strings.Split(name, "/")
+		dirName = strings.Join(splitedPath[:len(splitedPath)-1], "/")
+	}
+	entry, _, err := fl.createEntry(dirName, fileInfoPath{name, info, name})
+	return entry, err
+}
+
+// TextFormatter writes the layouts of ls, or JSON and CSV records.
+// DirURL gives the file:// URL of a directory, which links its "dir:"
+// header for Hyperlink; without it headers are not linked.
+type TextFormatter struct {
+	config *Config
+	DirURL func(dir string) string
+}
+
+func NewFormatter(config *Config) *TextFormatter {
+	return &TextFormatter{config: config}
+}
+
+func (f *TextFormatter) Header() string {
+	if f.config.Format == "csv" || f.config.Format == "tsv" {
+		return f.config.writeCSVHeader() + "\n"
+	}
+	return ""
+}
+
+func (f *TextFormatter) Format(entries []Entry) string {
+	if f.config.IsRecordFormat() {
+		return f.config.writeRecords("", -1, entries)
+	}
+	return f.config.writeListToOutput(entries)
+}
+
+// FormatDir writes the header, the total and the entries of a directory,
+// or its records for --json and --format=csv.
+func (f *TextFormatter) FormatDir(name string, entries []Entry, total int, header bool) string {
+	c := f.config
+	var output []string
+	if c.DirsFirst {
+		entries = SortDirsFirst(entries)
+	}
+	if c.IsRecordFormat() {
+		return c.writeRecords(name, total, entries)
+	}
+	if header {
+		quotedName := c.QuoteName(name)
+		if c.Hyperlink && f.DirURL != nil {
+			quotedName = c.hyperlink(quotedName, f.DirURL(name), false)
+		}
+		output = append(output, quotedName+":")
+	}
+	if c.Long || c.Blocks {
+		output = append(output, "total "+strconv.Itoa(total))
+	}
+	if toWrite := c.writeListToOutput(entries); len(toWrite) > 0 {
+		output = append(output, toWrite)
+	}
+	return strings.Join(output, "\n")
+}
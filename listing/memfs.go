@@ -0,0 +1,386 @@
+package listing
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// MemFS is an FS held in memory, to list synthetic trees. It starts as an
+// empty root directory and is filled with Mkdir, WriteFile, Symlink and
+// Mknod. Names are resolved from the root whether they start with "/" or
+// not, and ".." of the root is the root, as on a real file system.
+type MemFS struct {
+	lock   sync.RWMutex
+	root   *memFile
+	lastID uint64
+	users  map[uint32]string
+	groups map[uint32]string
+}
+
+type memFile struct {
+	mode     fs.FileMode
+	data     []byte
+	target   string
+	modTime  time.Time
+	birth    time.Time
+	attr     Attr
+	children map[string]*memFile
+}
+
+// the size of a directory and the unit of allocated blocks, as on ext4
+const memBlockSize = 4096
+
+func NewMemFS() *MemFS {
+	m := &MemFS{users: make(map[uint32]string), groups: make(map[uint32]string)}
+	m.root = m.newFile(fs.ModeDir | 0755)
+	m.root.attr.Nlink = 2
+	return m
+}
+
+func (m *MemFS) newFile(mode fs.FileMode) *memFile {
+	now := time.Now()
+	m.lastID++
+	f := &memFile{mode: mode, modTime: now, birth: now}
+	f.attr = Attr{Ino: m.lastID, Nlink: 1, Atime: now, Ctime: now}
+	if mode.IsDir() {
+		f.children = make(map[string]*memFile)
+	}
+	return f
+}
+
+func (f *memFile) size() int64 {
+	switch {
+	case f.mode.IsDir():
+		return memBlockSize
+	case f.mode&fs.ModeSymlink != 0:
+		return int64(len(f.target))
+	}
+	return int64(len(f.data))
+}
+
+// info describes f under the name it was looked up with.
+func (f *memFile) info(name string) *memInfo {
+	attr := f.attr
+	attr.Mode = unixMode(f.mode)
+	if f.mode.IsRegular() || f.mode.IsDir() {
+		attr.Blocks = (f.size() + memBlockSize - 1) / memBlockSize * (memBlockSize / 512)
+	}
+	return &memInfo{path.Base(name), f.size(), f.mode, f.modTime, attr}
+}
+
+// unixMode converts a fs.FileMode to the st_mode of stat(2).
+func unixMode(mode fs.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		m |= syscall.S_ISUID
+	}
+	if mode&fs.ModeSetgid != 0 {
+		m |= syscall.S_ISGID
+	}
+	if mode&fs.ModeSticky != 0 {
+		m |= syscall.S_ISVTX
+	}
+	switch {
+	case mode.IsDir():
+		m |= syscall.S_IFDIR
+	case mode&fs.ModeSymlink != 0:
+		m |= syscall.S_IFLNK
+	case mode&fs.ModeCharDevice != 0:
+		m |= syscall.S_IFCHR
+	case mode&fs.ModeDevice != 0:
+		m |= syscall.S_IFBLK
+	case mode&fs.ModeNamedPipe != 0:
+		m |= syscall.S_IFIFO
+	case mode&fs.ModeSocket != 0:
+		m |= syscall.S_IFSOCK
+	default:
+		m |= syscall.S_IFREG
+	}
+	return m
+}
+
+// lookup finds the file at name. Symlinks are followed in the
+// directories on the way, and in the last element with follow.
+func (m *MemFS) lookup(op, name string, follow bool) (*memFile, error) {
+	dirs := []*memFile{m.root}
+	todo := strings.Split(name, "/")
+	for links := 0; len(todo) > 0; {
+		elem := todo[0]
+		todo = todo[1:]
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if len(dirs) > 1 {
+				dirs = dirs[:len(dirs)-1]
+			}
+			continue
+		}
+		dir := dirs[len(dirs)-1]
+		if !dir.mode.IsDir() {
+			return nil, &fs.PathError{Op: op, Path: name, Err: syscall.ENOTDIR}
+		}
+		f, ok := dir.children[elem]
+		if !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		if f.mode&fs.ModeSymlink != 0 && (follow || len(todo) > 0) {
+			if links++; links > maxSymlinks {
+				return nil, &fs.PathError{Op: op, Path: name, Err: syscall.ELOOP}
+			}
+			if strings.HasPrefix(f.target, "/") {
+				dirs = dirs[:1]
+			}
+			todo = append(strings.Split(f.target, "/"), todo...)
+			continue
+		}
+		dirs = append(dirs, f)
+	}
+	return dirs[len(dirs)-1], nil
+}
+
+// create adds f to its parent directory as name.
+func (m *MemFS) create(op, name string, f *memFile) error {
+	clean := path.Clean("/" + name)
+	parent, err := m.lookup(op, path.Dir(clean), true)
+	if err != nil {
+		err.(*fs.PathError).Path = name
+		return err
+	}
+	if !parent.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: syscall.ENOTDIR}
+	}
+	base := path.Base(clean)
+	if _, ok := parent.children[base]; ok || clean == "/" {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrExist}
+	}
+	parent.children[base] = f
+	if f.mode.IsDir() {
+		f.attr.Nlink = 2
+		parent.attr.Nlink++
+	}
+	return nil
+}
+
+// Mkdir creates the directory name in an existing directory.
+func (m *MemFS) Mkdir(name string, perm fs.FileMode) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.create("mkdir", name, m.newFile(fs.ModeDir|perm&fs.ModePerm))
+}
+
+// WriteFile creates the regular file name. perm may hold the setuid,
+// setgid and sticky bits.
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	f := m.newFile(perm & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky))
+	f.data = append([]byte(nil), data...)
+	return m.create("open", name, f)
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+func (m *MemFS) Symlink(oldname, newname string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	f := m.newFile(fs.ModeSymlink | 0777)
+	f.target = oldname
+	return m.create("symlink", newname, f)
+}
+
+// Mknod creates a device, a named pipe or a socket, after the type bits of
+// mode. dev is the device number of a device.
+func (m *MemFS) Mknod(name string, mode fs.FileMode, dev uint64) error {
+	if mode&(fs.ModeDevice|fs.ModeNamedPipe|fs.ModeSocket) == 0 {
+		return &fs.PathError{Op: "mknod", Path: name, Err: syscall.EINVAL}
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	f := m.newFile(mode & (fs.ModeType | fs.ModePerm) &^ (fs.ModeDir | fs.ModeSymlink))
+	f.attr.Rdev = dev
+	return m.create("mknod", name, f)
+}
+
+// Chown changes the owner and group IDs of name, following a symlink.
+func (m *MemFS) Chown(name string, uid, gid uint32) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	f, err := m.lookup("chown", name, true)
+	if err != nil {
+		return err
+	}
+	f.attr.UID, f.attr.GID = uid, gid
+	return nil
+}
+
+// Chtimes changes the access and modification times of name, following a
+// symlink.
+func (m *MemFS) Chtimes(name string, atime, mtime time.Time) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	f, err := m.lookup("chtimes", name, true)
+	if err != nil {
+		return err
+	}
+	f.attr.Atime, f.modTime = atime, mtime
+	return nil
+}
+
+// AddUser and AddGroup name an ID for LookupUser and LookupGroup.
+func (m *MemFS) AddUser(uid uint32, name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.users[uid] = name
+}
+
+func (m *MemFS) AddGroup(gid uint32, name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.groups[gid] = name
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	f, err := m.lookup("open", name, true)
+	if err != nil {
+		return nil, err
+	}
+	h := &memHandle{name: name, info: f.info(name), reader: bytes.NewReader(f.data)}
+	if f.mode.IsDir() {
+		names := make([]string, 0, len(f.children))
+		for child := range f.children {
+			names = append(names, child)
+		}
+		sort.Strings(names)
+		h.entries = make([]fs.DirEntry, 0, len(names))
+		for _, child := range names {
+			h.entries = append(h.entries, fs.FileInfoToDirEntry(f.children[child].info(child)))
+		}
+	}
+	return h, nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	f, err := m.lookup("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return f.info(name), nil
+}
+
+func (m *MemFS) Lstat(name string) (fs.FileInfo, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	f, err := m.lookup("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return f.info(name), nil
+}
+
+func (m *MemFS) ReadLink(name string) (string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	f, err := m.lookup("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	if f.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: syscall.EINVAL}
+	}
+	return f.target, nil
+}
+
+func (m *MemFS) Attr(info fs.FileInfo) (Attr, bool) {
+	attr, ok := info.Sys().(Attr)
+	return attr, ok
+}
+
+func (m *MemFS) BirthTime(name string) (time.Time, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	f, err := m.lookup("statx", name, false)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return f.birth, true
+}
+
+func (m *MemFS) LookupUser(uid uint32) (string, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	name, ok := m.users[uid]
+	return name, ok
+}
+
+func (m *MemFS) LookupGroup(gid uint32) (string, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	name, ok := m.groups[gid]
+	return name, ok
+}
+
+// memInfo is a snapshot of a memFile, with its Attr as Sys.
+type memInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	attr    Attr
+}
+
+func (i *memInfo) Name() string       { return i.name }
+func (i *memInfo) Size() int64        { return i.size }
+func (i *memInfo) Mode() fs.FileMode  { return i.mode }
+func (i *memInfo) ModTime() time.Time { return i.modTime }
+func (i *memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *memInfo) Sys() interface{}   { return i.attr }
+
+// memHandle is an open file of a MemFS. The entries of a directory are
+// read when it is opened.
+type memHandle struct {
+	name    string
+	info    *memInfo
+	reader  *bytes.Reader
+	entries []fs.DirEntry
+}
+
+func (h *memHandle) Stat() (fs.FileInfo, error) {
+	return h.info, nil
+}
+
+func (h *memHandle) Read(b []byte) (int, error) {
+	if h.info.IsDir() {
+		return 0, &fs.PathError{Op: "read", Path: h.name, Err: syscall.EISDIR}
+	}
+	return h.reader.Read(b)
+}
+
+func (h *memHandle) Close() error {
+	return nil
+}
+
+func (h *memHandle) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !h.info.IsDir() {
+		return nil, &fs.PathError{Op: "readdirent", Path: h.name, Err: syscall.ENOTDIR}
+	}
+	if n > 0 && len(h.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n <= 0 || n > len(h.entries) {
+		n = len(h.entries)
+	}
+	entries := h.entries[:n]
+	h.entries = h.entries[n:]
+	return entries, nil
+}
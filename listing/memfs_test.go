@@ -0,0 +1,241 @@
+package listing
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memTree builds a synthetic tree with every file type, owned by named
+// and unnamed users, all modified at the same time in the past.
+func memTree(t *testing.T) *MemFS {
+	t.Helper()
+	m := NewMemFS()
+	m.AddUser(1000, "alice")
+	m.AddGroup(100, "users")
+	mtime := time.Date(2020, time.March, 4, 5, 6, 7, 0, time.Local)
+	for _, err := range []error{
+		m.Mkdir("home", 0755),
+		m.Mkdir("home/sub", 0750),
+		m.Mkdir("home/sub/deep", 0700),
+		m.Mkdir("home/empty", 0755),
+		m.WriteFile("home/a.txt", []byte("hello\n"), 0644),
+		m.WriteFile("home/run.sh", []byte("#!/bin/sh\n"), 0755),
+		m.WriteFile("home/.hidden", nil, 0600),
+		m.WriteFile("home/sub/big", make([]byte, 5000), 0644),
+		m.WriteFile("home/sub/deep/c", nil, 0644),
+		m.Symlink("a.txt", "home/link"),
+		m.Symlink("missing", "home/dangling"),
+		m.Symlink("/home/sub", "home/sublink"),
+		m.Mknod("home/tty", fs.ModeDevice|fs.ModeCharDevice|0620, 4<<8|1),
+		m.Mknod("home/fifo", fs.ModeNamedPipe|0644, 0),
+		m.Chown("home/a.txt", 1000, 100),
+		m.Chown("home/sub", 1000, 5000),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"home", "home/sub", "home/sub/deep", "home/empty", "home/a.txt",
+		"home/run.sh", "home/.hidden", "home/sub/big", "home/sub/deep/c", "home/tty", "home/fifo"} {
+		if err := m.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestMemFSReadDir(t *testing.T) {
+	config := DefaultConfig()
+	config.Long = true
+	config.All = true
+	// the symlinks are as old as the test
+	config.TimeFormatOld, config.TimeFormatRecent = "-", "-"
+	lister := NewFSLister(&config, memTree(t))
+	entries, total, err := lister.ReadDir("home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := NewFormatter(&config).FormatDir("home", entries, total, true)
+	want := `home:
+total 24
+drwxr-xr-x 4     0     0  4096 - .
+drwxr-xr-x 3     0     0  4096 - ..
+-rw-r--r-- 1 alice users     6 - a.txt
+lrwxrwxrwx 1     0     0     7 - dangling -> missing
+drwxr-xr-x 2     0     0  4096 - empty
+prw-r--r-- 1     0     0     0 - fifo
+-rw------- 1     0     0     0 - .hidden
+lrwxrwxrwx 1     0     0     5 - link -> a.txt
+-rwxr-xr-x 1     0     0    10 - run.sh
+drwxr-x--- 3 alice  5000  4096 - sub
+lrwxrwxrwx 1     0     0     9 - sublink -> /home/sub
+crw--w---- 1     0     0 4, 1 - tty`
+	if got != want {
+		t.Errorf("listing:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, _, err := lister.ReadDir("home/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadDir of a missing directory: %v", err)
+	}
+	if _, _, err := lister.ReadDir("home/a.txt"); err == nil {
+		t.Error("ReadDir of a file succeeded")
+	}
+}
+
+func TestMemFSStat(t *testing.T) {
+	config := DefaultConfig()
+	lister := NewFSLister(&config, memTree(t))
+	for _, test := range []struct {
+		name, permissions string
+		bytes             int64
+	}{
+		{"home/a.txt", "-rw-r--r--", 6},
+		// followed, like the operands of ls
+		{"home/link", "-rw-r--r--", 6},
+		{"/home/sublink/", "drwxr-x---", 4096},
+		{"home/sub/../../home/sub/big", "-rw-r--r--", 5000},
+	} {
+		entry, err := lister.Stat(test.name)
+		if err != nil {
+			t.Errorf("Stat(%s): %v", test.name, err)
+			continue
+		}
+		if entry.Permissions != test.permissions || entry.Bytes != test.bytes {
+			t.Errorf("Stat(%s) = %s %d, want %s %d", test.name, entry.Permissions, entry.Bytes, test.permissions, test.bytes)
+		}
+	}
+	if _, err := lister.Stat("home/dangling"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a dangling link: %v", err)
+	}
+}
+
+func TestMemFSWalk(t *testing.T) {
+	config := DefaultConfig()
+	config.One = true
+	lister := NewFSLister(&config, memTree(t))
+	formatter := NewFormatter(&config)
+	for _, jobs := range []int{1, 4} {
+		var blocks []string
+		err := Walk(lister, "home", jobs, func(name string, entries []Entry, total int, err error) error {
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, formatter.FormatDir(name, entries, total, true))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// symlinks to directories are not followed
+		want := `home:
+a.txt
+dangling
+empty
+fifo
+link
+run.sh
+sub
+sublink
+tty
+
+home/empty:
+
+home/sub:
+big
+deep
+
+home/sub/deep:
+c`
+		if got := strings.Join(blocks, "\n\n"); got != want {
+			t.Errorf("jobs=%d:\n%s\nwant:\n%s", jobs, got, want)
+		}
+	}
+}
+
+func TestMemFSTree(t *testing.T) {
+	config := DefaultConfig()
+	config.One = true
+	lister := NewFSLister(&config, memTree(t))
+	formatter := NewFormatter(&config)
+	root, err := lister.Stat("home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		level     int
+		dirsFirst bool
+		want      string
+	}{
+		{0, false, `home
+├── a.txt
+├── dangling
+├── empty
+├── fifo
+├── link
+├── run.sh
+├── sub
+│   ├── big
+│   └── deep
+│       └── c
+├── sublink
+└── tty`},
+		{1, true, `home
+├── empty
+├── sub
+├── a.txt
+├── dangling
+├── fifo
+├── link
+├── run.sh
+├── sublink
+└── tty`},
+	} {
+		list, errs := Tree(lister, root, test.level, test.dirsFirst)
+		if len(errs) != 0 {
+			t.Errorf("level %d: %v", test.level, errs)
+		}
+		if got := formatter.Format(list); got != test.want {
+			t.Errorf("level %d:\n%s\nwant:\n%s", test.level, got, test.want)
+		}
+	}
+}
+
+func TestMemFSHyperlink(t *testing.T) {
+	config := DefaultConfig()
+	config.Long = true
+	config.Hyperlink = true
+	config.Hostname = "host"
+	lister := NewFSLister(&config, memTree(t))
+	urls := make(map[string]string)
+	for _, dir := range []string{"home", "home/sublink/deep"} {
+		entries, _, err := lister.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			urls[e.Path] = e.URL + " " + e.LinkURL
+		}
+	}
+	for name, want := range map[string]string{
+		"home/a.txt": "file://host/home/a.txt ",
+		// symlinks are resolved, a dangling one up to its missing target
+		"home/link":           "file://host/home/a.txt file://host/home/a.txt",
+		"home/dangling":       "file://host/home/missing file://host/home/missing",
+		"home/sublink":        "file://host/home/sub file://host/home/sub",
+		"home/sublink/deep/c": "file://host/home/sub/deep/c ",
+	} {
+		if got := urls[name]; got != want {
+			t.Errorf("%s: URLs %q, want %q", name, got, want)
+		}
+	}
+
+	formatter := NewFormatter(&config)
+	formatter.DirURL = lister.URL
+	header := strings.SplitN(formatter.FormatDir("home/sublink", nil, 0, true), "\n", 2)[0]
+	if want := "\033]8;;file://host/home/sub\ahome/sublink\033]8;;\a:"; header != want {
+		t.Errorf("header %q, want %q", header, want)
+	}
+}
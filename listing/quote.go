@@ -0,0 +1,229 @@
+package listing
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var quotingStyles = []string{
+	"literal", "shell", "shell-always", "shell-escape", "shell-escape-always", "c", "escape",
+}
+
+// QuotingStyles returns the names accepted by --quoting-style.
+func QuotingStyles() []string {
+	return append([]string(nil), quotingStyles...)
+}
+
+func IsQuotingStyle(style string) bool {
+	for _, s := range quotingStyles {
+		if s == style {
+			return true
+		}
+	}
+	return false
+}
+
+// isShellSpecial reports whether c makes a shell word need quotes. "#" and
+// "~" only matter at the start of a word.
+func isShellSpecial(c rune, first bool) bool {
+	switch c {
+	case ' ', '!', '"', '$', '&', '\'', '(', ')', '*', ';', '<', '=', '>', '?',
+		'[', '\\', '^', '`', '|', '\t', '\n':
+		return true
+	case '#', '~':
+		return first
+	}
+	return false
+}
+
+func isPrintable(r rune, size int) bool {
+	if r == utf8.RuneError && size == 1 {
+		return false
+	}
+	// joiners are format characters but part of printable sequences
+	if r == zeroWidthJoiner || r == zeroWidthNonJoiner {
+		return true
+	}
+	return unicode.IsPrint(r)
+}
+
+// HideControlChars replaces every non-printable character with "?", as
+// -q does for the literal and shell styles.
+func HideControlChars(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		if isPrintable(r, size) {
+			b.WriteString(name[i : i+size])
+		} else {
+			b.WriteByte('?')
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// CEscape writes name with the backslash escapes of a C string literal.
+// Invalid UTF-8 and other non-printable bytes become octal escapes.
+func CEscape(name string, escapeSpace bool) string {
+	var b strings.Builder
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		switch r {
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\v':
+			b.WriteString(`\v`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			if escapeSpace {
+				b.WriteByte('"')
+			} else {
+				b.WriteString(`\"`)
+			}
+		case ' ':
+			if escapeSpace {
+				b.WriteString(`\ `)
+			} else {
+				b.WriteByte(' ')
+			}
+		default:
+			if isPrintable(r, size) {
+				b.WriteString(name[i : i+size])
+			} else {
+				for j := i; j < i+size; j++ {
+					fmt.Fprintf(&b, "\\%03o", name[j])
+				}
+			}
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// ShellQuote quotes name so that it can be pasted into a POSIX shell. With
+// escape set, control characters are written as $'\n' segments; otherwise
+// they have already been replaced by "?".
+func ShellQuote(name string, always, escape bool) string {
+	needsQuotes := always || name == "" || name == "{" || name == "}"
+	hasControl := false
+	hasSingleQuote := false
+	hasDoubleQuoteSpecial := false
+	for i, r := range name {
+		if isShellSpecial(r, i == 0) {
+			needsQuotes = true
+		}
+		switch r {
+		case '\'':
+			hasSingleQuote = true
+		case '"', '$', '`', '\\', '!':
+			hasDoubleQuoteSpecial = true
+		}
+		if !isPrintable(r, utf8.RuneLen(r)) {
+			hasControl = true
+			needsQuotes = true
+		}
+	}
+	if !utf8.ValidString(name) {
+		hasControl = true
+		needsQuotes = true
+	}
+	if !needsQuotes {
+		return name
+	}
+
+	if escape && hasControl {
+		var b strings.Builder
+		inQuotes, inEscapes := false, false
+		for i := 0; i < len(name); {
+			r, size := utf8.DecodeRuneInString(name[i:])
+			if isPrintable(r, size) {
+				if inEscapes {
+					b.WriteByte('\'')
+					inEscapes = false
+				}
+				if !inQuotes {
+					b.WriteByte('\'')
+					inQuotes = true
+				}
+				if r == '\'' {
+					b.WriteString(`'\''`)
+				} else {
+					b.WriteString(name[i : i+size])
+				}
+			} else {
+				if inQuotes {
+					b.WriteByte('\'')
+					inQuotes = false
+				}
+				if !inEscapes {
+					b.WriteString("$'")
+					inEscapes = true
+				}
+				b.WriteString(CEscape(name[i:i+size], false))
+			}
+			i += size
+		}
+		if inQuotes || inEscapes {
+			b.WriteByte('\'')
+		}
+		return b.String()
+	}
+
+	if hasSingleQuote && !hasDoubleQuoteSpecial {
+		return `"` + name + `"`
+	}
+	return "'" + strings.Replace(name, "'", `'\''`, -1) + "'"
+}
+
+// QuoteName formats a file name for the terminal according to
+// --quoting-style, -Q, -b, -q and -N.
+func (c *Config) QuoteName(name string) string {
+	switch c.QuotingStyle {
+	case "shell", "shell-always":
+		if c.HideControl {
+			name = HideControlChars(name)
+		}
+		return ShellQuote(name, c.QuotingStyle == "shell-always", false)
+	case "shell-escape", "shell-escape-always":
+		return ShellQuote(name, c.QuotingStyle == "shell-escape-always", true)
+	case "c":
+		return `"` + CEscape(name, false) + `"`
+	case "escape":
+		return CEscape(name, true)
+	}
+	if c.HideControl {
+		return HideControlChars(name)
+	}
+	return name
+}
+
+// isQuoted reports whether QuoteName wrapped name in quotes, which makes
+// the other names of a column or long listing shift by one space.
+func (c *Config) isQuoted(name string) bool {
+	switch c.QuotingStyle {
+	case "shell", "shell-escape":
+		return strings.ContainsAny(c.QuoteName(name)[:1], `'"$`)
+	}
+	return false
+}
+
+// alignsQuotes reports whether unquoted names get a leading space to line
+// up with quoted ones, which only matters when names are in columns.
+func (c *Config) alignsQuotes() bool {
+	return !c.One && !c.Commas && (c.Long || c.Width != math.MaxInt32)
+}
@@ -0,0 +1,241 @@
+package listing
+
+import (
+	"sort"
+	"strings"
+)
+
+// SortEntry is an entry being sorted, with the keys that are costly to
+// compute worked out once instead of on every comparison. index is its
+// position before sorting, which keeps the sort stable.
+type SortEntry struct {
+	*Entry
+	index    int
+	nameKey  string
+	extKey   string
+	ownerKey string
+	groupKey string
+	typeRank int
+}
+
+type SortKey struct {
+	compare func(a, b *SortEntry) int
+	reverse bool
+}
+
+var sortKeys = map[string]func(a, b *SortEntry) int{
+	"name":      CompareName,
+	"size":      CompareBytes,
+	"time":      CompareEpoch,
+	"mtime":     CompareMtime,
+	"atime":     CompareAtime,
+	"ctime":     CompareCtime,
+	"ext":       CompareExtension,
+	"extension": CompareExtension,
+	"version":   CompareVersion,
+	"owner":     CompareOwner,
+	"group":     CompareGroup,
+	"inode":     CompareInode,
+	"nlink":     CompareNlink,
+	"type":      CompareType,
+}
+
+// NewSortKey returns the comparator of a --sort key name such as "size",
+// which sorts in ascending order unless reverse is set. ok is false for an
+// unknown name.
+func NewSortKey(name string, reverse bool) (key SortKey, ok bool) {
+	compare, ok := sortKeys[name]
+	return SortKey{compare, reverse}, ok
+}
+
+// nameKeyFunc returns the function that gives the collation key of a
+// name in the locale of the config, or the name itself when names compare
+// as bytes.
+func (c *Config) nameKeyFunc() func(string) string {
+	if c.ByteCollation {
+		return func(name string) string { return name }
+	}
+	tailoring := tailorings[localeLanguage(c.Collation)]
+	return func(name string) string { return tailoredKey(name, tailoring) }
+}
+
+// Extension returns the text after the last dot of a name, dot included.
+func Extension(name string) string {
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		return name[index:]
+	}
+	return ""
+}
+
+// newSortEntry works out the keys of an entry. keyOf gives the keys of
+// names and idKeyOf those of owner and group names, which repeat from one
+// entry to the next.
+func newSortEntry(l *Entry, index int, keyOf, idKeyOf func(string) string) SortEntry {
+	entry := SortEntry{
+		Entry:    l,
+		index:    index,
+		nameKey:  keyOf(l.Name),
+		ownerKey: idKeyOf(l.Owner),
+		groupKey: idKeyOf(l.Group),
+		typeRank: typeOrder[FileType(*l)],
+	}
+	if ext := Extension(l.Name); ext != "" {
+		entry.extKey = keyOf(ext)
+	}
+	return entry
+}
+
+// sortChain returns the comparators of the sort order set by the options,
+// from the most to the least important. Names break the remaining ties.
+func (c *Config) sortChain() []SortKey {
+	var chain []SortKey
+	if len(c.SortKeys) > 0 {
+		chain = c.SortKeys[:len(c.SortKeys):len(c.SortKeys)]
+	} else if c.SortTime {
+		// newest first
+		chain = []SortKey{{CompareEpoch, true}}
+	} else if c.SortSize {
+		// largest first
+		chain = []SortKey{{CompareBytes, true}}
+	} else if c.SortExtension {
+		chain = []SortKey{{CompareExtension, false}}
+	} else if c.SortVersion {
+		chain = []SortKey{{CompareVersion, false}}
+	}
+	return append(chain, SortKey{CompareName, false})
+}
+
+// compareChain compares two entries by the first comparator of chain that
+// tells them apart, the whole order being reversed by -r. Entries equal
+// in every key keep their order.
+func (c *Config) compareChain(chain []SortKey, a, b *SortEntry) int {
+	for _, key := range chain {
+		result := key.compare(a, b)
+		if key.reverse {
+			result = -result
+		}
+		if result != 0 {
+			if c.SortReverse {
+				return -result
+			}
+			return result
+		}
+	}
+	return compareInt64(int64(a.index), int64(b.index))
+}
+
+// Sort sorts the listings in place in the order of the config.
+func (c *Config) Sort(listings []Entry) {
+	if c.Unsorted || len(listings) < 2 {
+		return
+	}
+	chain := c.sortChain()
+	keyOf := c.nameKeyFunc()
+	idKeys := make(map[string]string)
+	idKeyOf := func(id string) string {
+		key, ok := idKeys[id]
+		if !ok {
+			key = keyOf(id)
+			idKeys[id] = key
+		}
+		return key
+	}
+	entries := make([]SortEntry, len(listings))
+	for i := range listings {
+		entries[i] = newSortEntry(&listings[i], i, keyOf, idKeyOf)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return c.compareChain(chain, &entries[i], &entries[j]) < 0
+	})
+
+	sorted := make([]Entry, len(listings))
+	for i, e := range entries {
+		sorted[i] = *e.Entry
+	}
+	copy(listings, sorted)
+}
+
+func compareInt64(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func CompareName(a, b *SortEntry) int {
+	return strings.Compare(a.nameKey, b.nameKey)
+}
+
+// CompareExtension orders by the text after the last dot; names without
+// one come first.
+func CompareExtension(a, b *SortEntry) int {
+	return strings.Compare(a.extKey, b.extKey)
+}
+
+func CompareVersion(a, b *SortEntry) int {
+	return FileVersionCompare(a.Name, b.Name)
+}
+
+func CompareBytes(a, b *SortEntry) int {
+	return compareInt64(a.Bytes, b.Bytes)
+}
+
+func CompareEpoch(a, b *SortEntry) int {
+	return compareInt64(a.EpochNano, b.EpochNano)
+}
+
+func CompareMtime(a, b *SortEntry) int {
+	return compareInt64(a.MtimeNano, b.MtimeNano)
+}
+
+func CompareAtime(a, b *SortEntry) int {
+	return compareInt64(a.AtimeNano, b.AtimeNano)
+}
+
+func CompareCtime(a, b *SortEntry) int {
+	return compareInt64(a.CtimeNano, b.CtimeNano)
+}
+
+func CompareOwner(a, b *SortEntry) int {
+	return strings.Compare(a.ownerKey, b.ownerKey)
+}
+
+func CompareGroup(a, b *SortEntry) int {
+	return strings.Compare(a.groupKey, b.groupKey)
+}
+
+func CompareInode(a, b *SortEntry) int {
+	if a.Ino < b.Ino {
+		return -1
+	} else if a.Ino > b.Ino {
+		return 1
+	}
+	return 0
+}
+
+func CompareNlink(a, b *SortEntry) int {
+	if a.Nlink < b.Nlink {
+		return -1
+	} else if a.Nlink > b.Nlink {
+		return 1
+	}
+	return 0
+}
+
+var typeOrder = map[string]int{
+	"directory": 0,
+	"symlink":   1,
+	"file":      2,
+	"pipe":      3,
+	"socket":    4,
+	"block":     5,
+	"character": 6,
+}
+
+// CompareType puts directories first, then symlinks, regular files and
+// special files.
+func CompareType(a, b *SortEntry) int {
+	return compareInt64(int64(a.typeRank), int64(b.typeRank))
+}
@@ -0,0 +1,143 @@
+package listing
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func names(entries []Entry) string {
+	var list []string
+	for _, e := range entries {
+		list = append(list, e.Name)
+	}
+	return strings.Join(list, " ")
+}
+
+func TestSort(t *testing.T) {
+	entries := []Entry{
+		{Name: "b.txt", Permissions: "-rw-r--r--", Bytes: 10, EpochNano: 3, Owner: "root"},
+		{Name: "Ä.go", Permissions: "-rw-r--r--", Bytes: 30, EpochNano: 1, Owner: "alice"},
+		{Name: "a.txt", Permissions: "-rw-r--r--", Bytes: 10, EpochNano: 2, Owner: "bob"},
+		{Name: "dir", Permissions: "drwxr-xr-x", Bytes: 20, EpochNano: 4, Owner: "alice"},
+	}
+	key := func(name string) SortKey {
+		k, ok := NewSortKey(strings.TrimPrefix(name, "-"), strings.HasPrefix(name, "-"))
+		if !ok {
+			t.Fatalf("unknown sort key %s", name)
+		}
+		return k
+	}
+	for i, test := range []struct {
+		config func(c *Config)
+		want   string
+	}{
+		// punctuation only breaks ties, as in glibc locales
+		{func(c *Config) {}, "Ä.go a.txt b.txt dir"},
+		{func(c *Config) { c.ByteCollation = true }, "a.txt b.txt dir Ä.go"},
+		{func(c *Config) { c.SortReverse = true }, "dir b.txt a.txt Ä.go"},
+		{func(c *Config) { c.SortSize = true }, "Ä.go dir a.txt b.txt"},
+		{func(c *Config) { c.SortTime = true }, "dir b.txt a.txt Ä.go"},
+		{func(c *Config) { c.SortExtension = true }, "dir Ä.go a.txt b.txt"},
+		{func(c *Config) { c.SortKeys = []SortKey{key("size")} }, "a.txt b.txt dir Ä.go"},
+		{func(c *Config) { c.SortKeys = []SortKey{key("-size")} }, "Ä.go dir a.txt b.txt"},
+		{func(c *Config) { c.SortKeys = []SortKey{key("time")} }, "Ä.go a.txt b.txt dir"},
+		{func(c *Config) { c.SortKeys = []SortKey{key("owner"), key("-size")} }, "Ä.go dir a.txt b.txt"},
+		{func(c *Config) { c.SortKeys = []SortKey{key("type"), key("-size"), key("name")} }, "dir Ä.go a.txt b.txt"},
+		{func(c *Config) { c.SortKeys = []SortKey{key("type"), key("name")} }, "dir Ä.go a.txt b.txt"},
+		{func(c *Config) { c.Unsorted = true }, "b.txt Ä.go a.txt dir"},
+	} {
+		config := DefaultConfig()
+		test.config(&config)
+		list := append([]Entry(nil), entries...)
+		config.Sort(list)
+		if got := names(list); got != test.want {
+			t.Errorf("case %d: sorted %s, want %s", i, got, test.want)
+		}
+	}
+}
+
+func TestCollation(t *testing.T) {
+	for _, test := range []struct {
+		locale string
+		names  string
+		want   string
+	}{
+		{"en_US.UTF-8", "zebra Öl ägg åka apa", "ägg åka apa Öl zebra"},
+		{"sv_SE.UTF-8", "zebra Öl ägg åka apa", "apa zebra åka ägg Öl"},
+		{"de_DE", "zebra Öl ägg åka apa", "ägg åka apa Öl zebra"},
+		{"kk_KZ.UTF-8", "іні ыдыс үй ұл уақыт ёж ет", "ет ёж уақыт ұл үй ыдыс іні"},
+		{"ru_RU.UTF-8", "іні ыдыс ёж ет", "ёж ет іні ыдыс"},
+		{"tr_TR.UTF-8", "ip ılık hız dal çay can", "can çay dal hız ılık ip"},
+		{"es_ES@euro", "oso ñu nube", "nube ñu oso"},
+	} {
+		var entries []Entry
+		for _, name := range strings.Fields(test.names) {
+			entries = append(entries, Entry{Name: name, Permissions: "-rw-r--r--"})
+		}
+		config := DefaultConfig()
+		config.Collation = test.locale
+		config.Sort(entries)
+		if got := names(entries); got != test.want {
+			t.Errorf("%s: sorted %s, want %s", test.locale, got, test.want)
+		}
+	}
+}
+
+var words = []string{"report", "Отчёт", "café", "naïve", "data", "IMG_", "résumé", "übung", "Zebra", "ąčę"}
+
+// sortEntries makes n entries with names, sizes, times and owners that
+// repeat in the proportions of a large directory.
+func sortEntries(n int) []Entry {
+	r := rand.New(rand.NewSource(1))
+	owners := []string{"root", "alice", "bob", "www-data"}
+	entries := make([]Entry, n)
+	for i := range entries {
+		name := fmt.Sprintf("%s%d.%s", words[r.Intn(len(words))], r.Intn(n), []string{"txt", "go", "jpg", "tar.gz"}[r.Intn(4)])
+		entries[i] = Entry{
+			Name:        name,
+			Permissions: "-rw-r--r--",
+			Bytes:       r.Int63n(1 << 20),
+			EpochNano:   r.Int63(),
+			Owner:       owners[r.Intn(len(owners))],
+			Group:       owners[r.Intn(len(owners))],
+		}
+	}
+	return entries
+}
+
+func benchmarkSort(b *testing.B, n int) {
+	entries := sortEntries(n)
+	list := make([]Entry, n)
+	for _, bench := range []struct {
+		name   string
+		config func(c *Config)
+	}{
+		{"name", func(c *Config) {}},
+		{"size", func(c *Config) { c.SortSize = true }},
+		{"owner,group,-size", func(c *Config) {
+			for _, name := range []string{"owner", "group"} {
+				key, _ := NewSortKey(name, false)
+				c.SortKeys = append(c.SortKeys, key)
+			}
+			key, _ := NewSortKey("size", true)
+			c.SortKeys = append(c.SortKeys, key)
+		}},
+	} {
+		config := DefaultConfig()
+		bench.config(&config)
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				copy(list, entries)
+				b.StartTimer()
+				config.Sort(list)
+			}
+		})
+	}
+}
+
+func BenchmarkSort10k(b *testing.B)  { benchmarkSort(b, 10000) }
+func BenchmarkSort100k(b *testing.B) { benchmarkSort(b, 100000) }
+func BenchmarkSort1M(b *testing.B)   { benchmarkSort(b, 1000000) }
@@ -0,0 +1,78 @@
+package listing
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	atFdcwd           = -0x64
+	atSymlinkNofollow = 0x100
+	statxBtime        = 0x800
+)
+
+// the syscall package only knows statx(2) on a few architectures
+var sysStatx = map[string]uintptr{
+	"386":     383,
+	"amd64":   332,
+	"arm":     397,
+	"arm64":   291,
+	"loong64": 291,
+	"ppc64le": 383,
+	"riscv64": 291,
+	"s390x":   379,
+}[runtime.GOARCH]
+
+type statxTimestamp struct {
+	sec      int64
+	nsec     uint32
+	reserved int32
+}
+
+type statxT struct {
+	mask           uint32
+	blksize        uint32
+	attributes     uint64
+	nlink          uint32
+	uid            uint32
+	gid            uint32
+	mode           uint16
+	spare0         uint16
+	ino            uint64
+	size           uint64
+	blocks         uint64
+	attributesMask uint64
+	atime          statxTimestamp
+	btime          statxTimestamp
+	ctime          statxTimestamp
+	mtime          statxTimestamp
+	spare          [128]byte
+}
+
+// GetBirthTime asks statx(2) for the creation time of path without
+// following symlinks. ok is false when the kernel or the filesystem does
+// not record it.
+func GetBirthTime(path string) (t time.Time, ok bool) {
+	if sysStatx == 0 {
+		return t, false
+	}
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return t, false
+	}
+	var stx statxT
+	dirfd := atFdcwd
+	_, _, e := syscall.Syscall6(sysStatx,
+		uintptr(dirfd),
+		uintptr(unsafe.Pointer(p)),
+		atSymlinkNofollow,
+		statxBtime,
+		uintptr(unsafe.Pointer(&stx)),
+		0)
+	if e != 0 || stx.mask&statxBtime == 0 {
+		return t, false
+	}
+	return time.Unix(stx.btime.sec, int64(stx.btime.nsec)), true
+}
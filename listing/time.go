@@ -0,0 +1,42 @@
+package listing
+
+import (
+	"io/fs"
+	"time"
+)
+
+// the formats of the default "locale" time style, for files older than
+// six months and for recent ones
+const (
+	LocaleTimeOld    = "%b %e  %Y"
+	LocaleTimeRecent = "%b %e %H:%M"
+)
+
+// IsRecent reports whether t is less than six months old and not in the
+// future, which selects the recent time format.
+func IsRecent(t time.Time) bool {
+	now := time.Now()
+	return t.After(now.AddDate(0, -6, 0)) && t.Before(now.Add(5*time.Second))
+}
+
+func (c *Config) formatTime(t time.Time) string {
+	if IsRecent(t) {
+		return Strftime(c.TimeFormatRecent, t)
+	}
+	return Strftime(c.TimeFormatOld, t)
+}
+
+// getFileTime returns the timestamp picked with -u, -c or --time, which is
+// both displayed and used by -t. ok is false for a birth time the
+// filesystem does not know, given as the zero time.
+func (c *Config) getFileTime(info fs.FileInfo, attr Attr, birth time.Time) (t time.Time, ok bool) {
+	switch c.TimeField {
+	case "atime":
+		return attr.Atime, true
+	case "ctime":
+		return attr.Ctime, true
+	case "birth":
+		return birth, !birth.IsZero()
+	}
+	return info.ModTime(), true
+}
@@ -0,0 +1,108 @@
+package listing
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// connectors drawn before the entries of a --tree
+const (
+	treeBranch = "├── "
+	treeLast   = "└── "
+	treePipe   = "│   "
+	treeSpace  = "    "
+)
+
+// ParseLevel validates a -L/--level value, the number of directory levels
+// shown below each operand.
+func ParseLevel(value string) (int, error) {
+	level, err := strconv.Atoi(value)
+	if err != nil || level < 1 {
+		return 0, fmt.Errorf("invalid level: '%s'", value)
+	}
+	return level, nil
+}
+
+// DirError is a directory that could not be read.
+type DirError struct {
+	Dir string
+	Err error
+}
+
+func (e *DirError) Error() string {
+	return fmt.Sprintf("cannot open directory %s: %v", e.Dir, UnwrapError(e.Err))
+}
+
+type treeWalk struct {
+	lister    Lister
+	level     int
+	dirsFirst bool
+	errs      []error
+}
+
+// add appends the entries of the directory dir to list, each with the
+// connectors that put it in the tree, and descends into the
+// subdirectories down to level. Symlinks to directories are not followed.
+func (t *treeWalk) add(list []Entry, dir string, indent string, depth int) []Entry {
+	listings, _, err := t.lister.ReadDir(dir)
+	if err != nil {
+		t.errs = append(t.errs, &DirError{dir, err})
+	}
+	children := listings[:0]
+	for _, l := range listings {
+		if l.Name != "." && l.Name != ".." {
+			children = append(children, l)
+		}
+	}
+	if t.dirsFirst {
+		children = SortDirsFirst(children)
+	}
+
+	for i, l := range children {
+		last := i == len(children)-1
+		if last {
+			l.TreePrefix = indent + treeLast
+		} else {
+			l.TreePrefix = indent + treeBranch
+		}
+		list = append(list, l)
+
+		if l.Permissions[0] == 'd' && (t.level == 0 || depth < t.level) {
+			sub := dir + "/" + l.Name
+			if last {
+				list = t.add(list, sub, indent+treeSpace, depth+1)
+			} else {
+				list = t.add(list, sub, indent+treePipe, depth+1)
+			}
+		}
+	}
+	return list
+}
+
+// UnwrapError drops the "open path:" part of a *os.PathError, which is
+// already in the message around it.
+func UnwrapError(err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		return pathErr.Err
+	}
+	return err
+}
+
+// Tree returns root followed by everything below it, down to level
+// directories or without a limit for 0. The entries carry the connectors
+// of the tree in TreePrefix, to be laid out one per line. The directories
+// that could not be read are returned as *DirError.
+func Tree(lister Lister, root Entry, level int, dirsFirst bool) ([]Entry, []error) {
+	list := []Entry{root}
+	if !root.IsDir() {
+		return list, nil
+	}
+	dirName := strings.TrimRight(root.Path, "/")
+	if dirName == "" {
+		dirName = "/"
+	}
+	t := &treeWalk{lister: lister, level: level, dirsFirst: dirsFirst}
+	return t.add(list, dirName, "", 1), t.errs
+}
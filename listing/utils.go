@@ -0,0 +1,818 @@
+package listing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseColors reads a LS_COLORS value into the escape sequences of every
+// type and extension.
+func ParseColors(value string) map[string]string {
+	colors := make(map[string]string)
+	colors["end"] = "\x1b[0m"
+
+	envColors := strings.Split(value, ":")
+	for _, color := range envColors {
+		if color == "" {
+			continue
+		}
+
+		tmp := strings.Split(color, "=")
+		colorID := fmt.Sprintf("\x1b[%sm", tmp[1])
+		colorType := tmp[0]
+
+		if colorType == "rs" {
+			colors["end"] = colorID
+		} else if colorType == "di" {
+			colors["directory"] = colorID
+		} else if colorType == "ln" {
+			colors["symlink"] = colorID
+		} else if colorType == "mh" {
+			colors["multi_hardlink"] = colorID
+		} else if colorType == "pi" {
+			colors["pipe"] = colorID
+		} else if colorType == "so" {
+			colors["socket"] = colorID
+		} else if colorType == "bd" {
+			colors["block"] = colorID
+		} else if colorType == "cd" {
+			colors["character"] = colorID
+		} else if colorType == "or" {
+			colors["link_orphan"] = colorID
+		} else if colorType == "mi" {
+			colors["link_orphan_target"] = colorID
+		} else if colorType == "su" {
+			colors["executable_suid"] = colorID
+		} else if colorType == "sg" {
+			colors["executable_sgid"] = colorID
+		} else if colorType == "tw" {
+			colors["directory_o+w_sticky"] = colorID
+		} else if colorType == "ow" {
+			colors["directory_o+w"] = colorID
+		} else if colorType == "st" {
+			colors["directory_sticky"] = colorID
+		} else if colorType == "ex" {
+			colors["executable"] = colorID
+		} else {
+			colors[colorType] = colorID
+		}
+	}
+	return colors
+}
+
+type fileInfoPath struct {
+	path     string
+	info     os.FileInfo
+	fullPath string
+}
+
+func (fl *FileLister) createEntry(dirName string, pathInfo fileInfoPath) (Entry, int, error) {
+	c := fl.config
+	var list Entry
+	list.Path = pathInfo.fullPath
+	list.Permissions = pathInfo.info.Mode().String()
+	if pathInfo.info.Mode()&os.ModeSymlink == os.ModeSymlink {
+		// fmt.Println(dirName, pathInfo)
+		list.Permissions = strings.Replace(list.Permissions, "L", "l", 1)
+		var path string
+
+		path = fmt.Sprintf("%s", dirName)
+
+		//fmt.Println(path)
+		if path == "" {
+			path = "."
+		}
+		link, err := fl.fs.ReadLink(fmt.Sprintf("%s/%s", path, pathInfo.path))
+		if err != nil && !os.IsPermission(err) {
+			return list, 0, err
+		}
+		// fmt.Println(strings.Join(splitedPath[:len(splitedPath)-1], "/"))
+		// fmt.Println(dirName)
+		list.LinkName = link
+		var linkPath string
+		if len(dirName) == 0 {
+			linkPath = fmt.Sprintf("%s", link)
+		} else {
+			if len(link) > 0 && link[0] == '/' {
+				linkPath = fmt.Sprintf("%s", link)
+			} else {
+				linkPath = fmt.Sprintf("%s/%s", path, link)
+			}
+		}
+		//fmt.Println(linkPath)
+		list.LinkColor, err = fl.linkColor(linkPath)
+		if err != nil {
+			return list, 0, err
+		}
+		target, err := fl.fs.Stat(linkPath)
+		if err != nil && !os.IsPermission(err) {
+			if os.IsNotExist(err) {
+				list.LinkOrphan = true
+			}
+		} else if err == nil {
+			list.LinkMode = target.Mode()
+		}
+	} else if list.Permissions[0] == 'D' {
+		list.Permissions = list.Permissions[1:]
+	} else if list.Permissions[0:2] == "ug" {
+		list.Permissions = strings.Replace(list.Permissions, "ug", "-", 1)
+		list.Permissions = fmt.Sprintf("%ss%ss%s",
+			list.Permissions[0:3],
+			list.Permissions[4:6],
+			list.Permissions[7:])
+	} else if list.Permissions[0] == 'u' {
+		list.Permissions = strings.Replace(list.Permissions, "u", "-", 1)
+		list.Permissions = fmt.Sprintf("%ss%s",
+			list.Permissions[0:3],
+			list.Permissions[4:])
+	} else if list.Permissions[0] == 'g' {
+		list.Permissions = strings.Replace(list.Permissions, "g", "-", 1)
+		list.Permissions = fmt.Sprintf("%ss%s",
+			list.Permissions[0:6],
+			list.Permissions[7:])
+	} else if list.Permissions[0:2] == "dt" {
+		list.Permissions = strings.Replace(list.Permissions, "dt", "d", 1)
+		list.Permissions = fmt.Sprintf("%st",
+			list.Permissions[0:len(list.Permissions)-1])
+	} else if list.Permissions[0] == 'S' {
+		list.Permissions = "s" + list.Permissions[1:]
+	}
+
+	stat, ok := fl.fs.Attr(pathInfo.info)
+	if !ok {
+		return list, 0, fmt.Errorf("syscall failed")
+	}
+
+	hardLinksNum := stat.Nlink
+	list.HardLinks = fmt.Sprintf("%d", hardLinksNum)
+	list.Mode = stat.Mode
+	list.Nlink = hardLinksNum
+	list.UID = stat.UID
+	list.GID = stat.GID
+
+	// IDs without a passwd/group entry (containers, NFS) are shown as numbers
+	list.Owner = fmt.Sprintf("%d", stat.UID)
+	if !c.Numeric {
+		if owner, ok := fl.fs.LookupUser(stat.UID); ok {
+			list.Owner = owner
+		}
+	}
+
+	list.Group = strconv.Itoa(int(stat.GID))
+	if !c.Numeric {
+		if group, ok := fl.fs.LookupGroup(stat.GID); ok {
+			list.Group = group
+		}
+	}
+
+	if c.Human {
+		list.Size = HumanSize(pathInfo.info.Size())
+	} else {
+		list.Size = fmt.Sprintf("%d", pathInfo.info.Size())
+	}
+	list.Ino = stat.Ino
+	list.Inode = fmt.Sprintf("%d", stat.Ino)
+	list.BlockCount = stat.Blocks / 2
+	if c.Human {
+		list.Blocks = HumanSize(stat.Blocks * 512)
+	} else {
+		list.Blocks = fmt.Sprintf("%d", list.BlockCount)
+	}
+
+	list.Bytes = pathInfo.info.Size()
+	list.MtimeNano = pathInfo.info.ModTime().UnixNano()
+	list.AtimeNano = stat.Atime.UnixNano()
+	list.CtimeNano = stat.Ctime.UnixNano()
+	var birth time.Time
+	if c.JSON || c.TimeField == "birth" {
+		if birth, ok = fl.fs.BirthTime(pathInfo.fullPath); ok {
+			list.BirthNano = birth.UnixNano()
+		}
+	}
+
+	fileTime, ok := c.getFileTime(pathInfo.info, stat, birth)
+	if ok {
+		list.EpochNano = fileTime.UnixNano()
+		list.Timestamp = c.formatTime(fileTime)
+	} else {
+		list.Timestamp = "?"
+	}
+
+	list.Name = pathInfo.path
+
+	if pathInfo.info.Mode()&os.ModeCharDevice == os.ModeCharDevice {
+		list.IsCharacter = true
+	} else if pathInfo.info.Mode()&os.ModeDevice == os.ModeDevice {
+		list.Permissions = "b" + list.Permissions
+		list.IsBlock = true
+	} else if pathInfo.info.Mode()&os.ModeNamedPipe == os.ModeNamedPipe {
+		list.IsPipe = true
+	} else if pathInfo.info.Mode()&os.ModeSocket == os.ModeSocket {
+		list.IsSocket = true
+	}
+	if list.IsBlock || list.IsCharacter {
+		list.Major = fmt.Sprintf("%d", uint64(stat.Rdev/256))
+		list.Minor = fmt.Sprintf("%d", uint64(stat.Rdev%256))
+	}
+	if c.Hyperlink {
+		list.URL = fl.URL(pathInfo.fullPath)
+		if list.Permissions[0] == 'l' {
+			target := list.LinkName
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(list.Path), target)
+			}
+			list.LinkURL = fl.URL(target)
+		}
+	}
+	if c.Git && c.Long && fl.git != nil {
+		status, err := fl.git.Status(pathInfo.fullPath, list.Permissions[0] == 'd')
+		if err != nil {
+			fl.warn(err)
+		}
+		list.GitStatus = status
+	}
+	return list, int(stat.Blocks) / 2, nil
+
+}
+
+func HumanSize(bytes int64) string {
+	size := float64(bytes)
+
+	count := 0
+	for size >= 1.0 {
+		size /= 1024
+		count++
+	}
+
+	if count < 0 {
+		count = 0
+	} else if count > 0 {
+		size *= 1024
+		count--
+	}
+
+	var suffix string
+	if count == 0 {
+		suffix = "B"
+	} else if count == 1 {
+		suffix = "K"
+	} else if count == 2 {
+		suffix = "M"
+	} else if count == 3 {
+		suffix = "G"
+	} else if count == 4 {
+		suffix = "T"
+	} else if count == 5 {
+		suffix = "P"
+	} else if count == 6 {
+		suffix = "E"
+	} else {
+		suffix = "?"
+	}
+
+	sizeStr := ""
+	if count == 0 {
+		sizeStr = fmt.Sprintf("%d%s", int64(size), suffix)
+	} else {
+		sizeStr = fmt.Sprintf("%.1f%s", size, suffix)
+	}
+
+	if len(sizeStr) > 3 &&
+		sizeStr[len(sizeStr)-3:len(sizeStr)-1] == ".0" {
+		sizeStr = sizeStr[0:len(sizeStr)-3] + suffix
+	}
+
+	return sizeStr
+}
+
+// writePrefix returns the -i and -s columns that go in front of a name in
+// every layout, right justified to the widths of the whole listing.
+func (c *Config) writePrefix(l Entry, inodeWidth, blocksWidth int) string {
+	str := ""
+	if c.Inode {
+		for i := 0; i < inodeWidth-len(l.Inode); i++ {
+			str += " "
+		}
+		str += l.Inode
+		str += " "
+	}
+	if c.Blocks {
+		for i := 0; i < blocksWidth-len(l.Blocks); i++ {
+			str += " "
+		}
+		str += l.Blocks
+		str += " "
+	}
+	return str
+}
+
+func (c *Config) writeListToOutput(list []Entry) string {
+	if len(list) == 0 {
+		return ""
+	}
+	var output []string
+
+	inodeWidth, blocksWidth := 0, 0
+	for _, l := range list {
+		if len(l.Inode) > inodeWidth {
+			inodeWidth = len(l.Inode)
+		}
+		if len(l.Blocks) > blocksWidth {
+			blocksWidth = len(l.Blocks)
+		}
+	}
+	prefixWidth := len(c.writePrefix(Entry{}, inodeWidth, blocksWidth))
+
+	// when some names are quoted, the others get a space to line up
+	quotePad := false
+	for _, l := range list {
+		if c.isQuoted(l.Name) && c.alignsQuotes() {
+			quotePad = true
+			break
+		}
+	}
+	writePad := func(l Entry) string {
+		if quotePad && !c.isQuoted(l.Name) {
+			return " "
+		}
+		return ""
+	}
+
+	if c.Long {
+		var (
+			permissionsWidth int = 0
+			hardLinksWidth   int = 0
+			ownerWidth       int = 0
+			groupWidth       int = 0
+			sizeWidth        int = 0
+			majorWidth       int = 0
+			minorWidth       int = 0
+			timestampWidth   int = 0
+			gitColumn            = false
+		)
+
+		for _, l := range list {
+			if l.GitStatus != "" {
+				gitColumn = true
+			}
+			if len(l.Permissions) > permissionsWidth {
+				permissionsWidth = len(l.Permissions)
+			}
+			if len(l.HardLinks) > hardLinksWidth {
+				hardLinksWidth = len(l.HardLinks)
+			}
+			if DisplayWidth(l.Owner) > ownerWidth {
+				ownerWidth = DisplayWidth(l.Owner)
+			}
+			if DisplayWidth(l.Group) > groupWidth {
+				groupWidth = DisplayWidth(l.Group)
+			}
+			if len(l.Major) > majorWidth {
+				majorWidth = len(l.Major)
+			}
+			if len(l.Minor) > minorWidth {
+				minorWidth = len(l.Minor)
+			}
+			if len(l.Size) > sizeWidth {
+				sizeWidth = len(l.Size)
+			}
+			if DisplayWidth(l.Timestamp) > timestampWidth {
+				timestampWidth = DisplayWidth(l.Timestamp)
+			}
+			if l.IsBlock || l.IsCharacter && len(l.Major)+len(l.Minor)+3 > sizeWidth {
+				sizeWidth = len(l.Major) + len(l.Minor) + 3
+			}
+		}
+
+		for _, l := range list {
+			str := c.writePrefix(l, inodeWidth, blocksWidth)
+			// permissions
+			str += l.Permissions
+			for i := 0; i < permissionsWidth-len(l.Permissions); i++ {
+				str += " "
+			}
+			str += " "
+
+			// number of hard links (right justified)
+			for i := 0; i < hardLinksWidth-len(l.HardLinks); i++ {
+				str += " "
+			}
+			str += l.HardLinks
+			str += " "
+
+			// owner
+			if !c.NoOwner {
+				str += WriteID(l.Owner, l.Owner == strconv.Itoa(int(l.UID)), ownerWidth)
+			}
+
+			// group
+			if !c.NoGroup {
+				str += WriteID(l.Group, l.Group == strconv.Itoa(int(l.GID)), groupWidth)
+			}
+
+			// author, which is always the owner on Linux
+			if c.Author {
+				str += WriteID(l.Owner, l.Owner == strconv.Itoa(int(l.UID)), ownerWidth)
+			}
+
+			// size
+			if l.IsBlock || l.IsCharacter {
+				for i := 0; i < majorWidth-len(l.Major); i++ {
+					str += " "
+				}
+				str += l.Major
+				str += ", "
+				for i := 0; i < minorWidth-len(l.Minor); i++ {
+					str += " "
+				}
+				str += l.Minor
+				str += " "
+			} else {
+				for i := 0; i < sizeWidth-len(l.Size); i++ {
+					str += " "
+				}
+				str += l.Size
+				str += " "
+			}
+
+			// time, or "?" right justified for an unknown birth time
+			if l.Timestamp == "?" {
+				for i := 0; i < timestampWidth-len(l.Timestamp); i++ {
+					str += " "
+				}
+			}
+			str += l.Timestamp
+			str += " "
+
+			// git status, when some entry is in a work tree
+			if gitColumn {
+				if l.GitStatus == "" {
+					str += "  "
+				}
+				str += l.GitStatus + " "
+			}
+
+			// name
+			str += l.TreePrefix
+			str += c.writeIcon(l)
+			str += writePad(l)
+			str += c.writeName(l)
+			output = append(output, str)
+		}
+	} else if c.One {
+		for _, l := range list {
+			output = append(output, c.writePrefix(l, inodeWidth, blocksWidth)+l.TreePrefix+c.writeIcon(l)+c.writeName(l))
+		}
+	} else if c.Commas {
+		// names separated by ", ", wrapped before the line gets too long
+		var str strings.Builder
+		pos := 0
+		for i, l := range list {
+			width := prefixWidth + c.iconWidth(l) + c.nameWidth(l)
+			if i != 0 {
+				if pos+width+2 < c.Width {
+					str.WriteString(", ")
+					pos += 2
+				} else {
+					str.WriteString(",\n")
+					pos = 0
+				}
+			}
+			str.WriteString(c.writePrefix(l, inodeWidth, blocksWidth))
+			str.WriteString(c.writeIcon(l))
+			str.WriteString(c.writeName(l))
+			pos += width
+		}
+		output = append(output, str.String())
+	} else {
+		widths := make([]int, len(list))
+		for i, l := range list {
+			widths[i] = prefixWidth + c.iconWidth(l) + len(writePad(l)) + c.nameWidth(l)
+		}
+		colWidth := ColumnWidths(widths, c.Width, c.Across)
+		cols := len(colWidth)
+		rows := (len(list) + cols - 1) / cols
+
+		writeEntry := func(i int) string {
+			return c.writePrefix(list[i], inodeWidth, blocksWidth) + c.writeIcon(list[i]) + writePad(list[i]) + c.writeName(list[i])
+		}
+		var str strings.Builder
+		if c.Across {
+			pos := 0
+			for i := range list {
+				col := i % cols
+				if i != 0 && col == 0 {
+					str.WriteString("\n")
+					pos = 0
+				} else if i != 0 {
+					str.WriteString(c.indent(pos+widths[i-1], pos+colWidth[col-1]))
+					pos += colWidth[col-1]
+				}
+				str.WriteString(writeEntry(i))
+			}
+		}
+		for r := 0; r < rows && !c.Across; r++ {
+			pos := 0
+			for col, i := 0, r; i < len(list); col, i = col+1, i+rows {
+				if i != r {
+					str.WriteString(c.indent(pos+widths[i-rows], pos+colWidth[col-1]))
+					pos += colWidth[col-1]
+				}
+				str.WriteString(writeEntry(i))
+			}
+			if r != rows-1 {
+				str.WriteString("\n")
+			}
+		}
+		output = append(output, str.String())
+	}
+	return strings.Join(output, "\n")
+}
+
+// IsRecordFormat reports whether the output is meant for other programs
+// rather than a terminal, in which case headers and totals go into the
+// records themselves.
+func (c *Config) IsRecordFormat() bool {
+	return c.JSON || c.Format == "csv" || c.Format == "tsv"
+}
+
+func (c *Config) writeRecords(path string, total int, list []Entry) string {
+	if c.JSON {
+		return WriteListToJSON(path, total, list)
+	}
+	return c.writeListToCSV(path, list)
+}
+
+// WriteID pads an owner or group column. Names are left justified and
+// bare numeric IDs are right justified, as in GNU ls.
+func WriteID(id string, numeric bool, width int) string {
+	str := ""
+	if numeric {
+		for i := 0; i < width-DisplayWidth(id); i++ {
+			str += " "
+		}
+		str += id
+	} else {
+		str += id
+		for i := 0; i < width-DisplayWidth(id); i++ {
+			str += " "
+		}
+	}
+	return str + " "
+}
+
+func (c *Config) writeName(l Entry) string {
+	str := ""
+	quotedName := c.QuoteName(l.Name)
+	if c.Hyperlink {
+		quotedName = c.hyperlink(quotedName, l.URL, c.isQuoted(l.Name) && c.alignsQuotes())
+	}
+	if c.Color {
+		appliedColor := false
+
+		hardLinksNum, _ := strconv.Atoi(l.HardLinks)
+
+		// "file.Name.txt" -> "*.txt"
+		name := strings.Split(l.Name, ".")
+		extension := ""
+		if len(name) > 1 {
+			extension = fmt.Sprintf("*.%s", name[len(name)-1])
+		}
+
+		if extension != "" && c.Colors[extension] != "" {
+			str += c.Colors[extension]
+			appliedColor = true
+		} else if c.getColor(l) != "" {
+			str += c.getColor(l)
+			appliedColor = true
+		} else if hardLinksNum > 1 { // multiple hardlinks
+			str += c.Colors["multi_hardlink"]
+			appliedColor = true
+		}
+
+		str += quotedName
+		if appliedColor {
+			str += c.Colors["end"]
+		}
+	} else {
+		str += quotedName
+	}
+
+	if l.Permissions[0] == 'l' && c.Long {
+		quotedLink := c.QuoteName(l.LinkName)
+		if c.Hyperlink {
+			quotedLink = c.hyperlink(quotedLink, l.LinkURL, false)
+		}
+		if l.LinkOrphan {
+			str += fmt.Sprintf(" -> %s%s%s",
+				c.Colors["link_orphan_target"],
+				quotedLink,
+				c.Colors["end"])
+		} else {
+			str += fmt.Sprintf(" -> %s%s%s", l.LinkColor, quotedLink, c.Colors["end"])
+			str += c.modeIndicator(l.LinkMode)
+		}
+	} else {
+		str += c.indicator(l)
+	}
+	return str
+}
+
+// nameWidth is the number of terminal cells writeName takes up, without
+// colors.
+func (c *Config) nameWidth(l Entry) int {
+	return DisplayWidth(c.QuoteName(l.Name)) + len(c.indicator(l))
+}
+
+// ReadDir lists the directory name, sorted, with "." and ".." for -a.
+// Unsorted entries keep the directory order, but "." and ".." always come
+// first: reading a directory in Go skips them, so where they were is not
+// known.
+func (fl *FileLister) ReadDir(name string) ([]Entry, int, error) {
+	c := fl.config
+	l := make([]Entry, 0)
+	size := 0
+
+	if c.All {
+		info, err := fl.fs.Stat(name)
+		if err != nil {
+			return l, 0, err
+		}
+		list, blocksize, err := fl.createEntry(name,
+			fileInfoPath{".", info, name})
+		size += blocksize
+		if err != nil {
+			return l, 0, err
+		}
+
+		infodot, err := fl.fs.Stat(name + "/..")
+		if err != nil {
+			return l, 0, err
+		}
+
+		listDot, blocksize, err := fl.createEntry(name,
+			fileInfoPath{"..", infodot, name + "/.."})
+		size += blocksize
+		if err != nil {
+			return l, 0, err
+		}
+
+		l = append(l, list)
+		l = append(l, listDot)
+	}
+	files, err := readDir(fl.fs, name)
+	// for _, v := range files {
+	// 	fmt.Println(v.Name())
+	// }
+	if err != nil {
+		return l, 0, err
+	}
+
+	for _, f := range files {
+		if []rune(f.Name())[0] == rune('.') && !c.All {
+			continue
+		}
+
+		_l, blocksize, err := fl.createEntry(name,
+			fileInfoPath{f.Name(), f, name + "/" + f.Name()})
+		size += blocksize
+		if err != nil && !os.IsPermission(err) {
+			return l, 0, err
+		}
+		l = append(l, _l)
+	}
+	c.Sort(l)
+	return l, size, nil
+}
+
+func SortDirsFirst(listings []Entry) []Entry {
+
+	sortedList := make([]Entry, 0)
+
+	for _, l := range listings {
+		if l.Permissions[0] == 'd' {
+			sortedList = append(sortedList, l)
+		}
+	}
+	for _, l := range listings {
+		if l.Permissions[0] != 'd' {
+			sortedList = append(sortedList, l)
+		}
+	}
+
+	return sortedList
+}
+
+func (c *Config) getColor(l Entry) string {
+	return c.Colors[ColorType(l)]
+}
+
+// ColorType classifies an entry by the c.Colors key that colors it, or ""
+// for a regular file.
+func ColorType(l Entry) string {
+	if l.Permissions[0] == 'd' &&
+		l.Permissions[8] == 'w' && l.Permissions[9] == 't' {
+		return "directory_o+w_sticky"
+	} else if l.Permissions[0] == 'd' && l.Permissions[9] == 't' {
+		return "directory_sticky"
+	} else if l.Permissions[0] == 'd' && l.Permissions[8] == 'w' {
+		return "directory_o+w"
+	} else if l.Permissions[0] == 'd' { // directory
+		return "directory"
+	} else if l.Permissions[0] == 'l' && l.LinkOrphan { // orphan link
+		return "link_orphan"
+	} else if l.Permissions[0] == 'l' { // symlink
+		return "symlink"
+	} else if l.Permissions[3] == 's' { // setuid
+		return "executable_suid"
+	} else if l.Permissions[6] == 's' { // setgid
+		return "executable_sgid"
+	} else if strings.Contains(l.Permissions, "x") { // executable
+		return "executable"
+	} else if l.IsSocket { // socket
+		return "socket"
+	} else if l.IsPipe { // pipe
+		return "pipe"
+	} else if l.IsBlock { // block
+		return "block"
+	} else if l.IsCharacter { // character
+		return "character"
+	}
+	return ""
+}
+
+func FileType(l Entry) string {
+	switch {
+	case l.Permissions[0] == 'd':
+		return "directory"
+	case l.Permissions[0] == 'l':
+		return "symlink"
+	case l.IsSocket:
+		return "socket"
+	case l.IsPipe:
+		return "pipe"
+	case l.IsBlock:
+		return "block"
+	case l.IsCharacter:
+		return "character"
+	}
+	return "file"
+}
+
+func (fl *FileLister) linkColor(linkPath string) (string, error) {
+	c := fl.config
+	info, err := fl.fs.Stat(linkPath)
+	var linkOrphan, isCharacter, isBlock, isPipe, isSocket bool
+	if err != nil && !os.IsPermission(err) {
+		if os.IsNotExist(err) {
+			linkOrphan = true
+			return "", nil
+		} else {
+			return "", err
+		}
+	}
+	permissions := info.Mode().String()
+	if info.Mode()&os.ModeCharDevice == os.ModeCharDevice {
+		isCharacter = true
+	} else if info.Mode()&os.ModeDevice == os.ModeDevice {
+		isBlock = true
+	} else if info.Mode()&os.ModeNamedPipe == os.ModeNamedPipe {
+		isPipe = true
+	} else if info.Mode()&os.ModeSocket == os.ModeSocket {
+		isSocket = true
+	}
+
+	if permissions[0] == 'd' &&
+		permissions[8] == 'w' && permissions[9] == 't' {
+		return c.Colors["directory_o+w_sticky"], nil
+	} else if permissions[0] == 'd' && permissions[9] == 't' {
+		return c.Colors["directory_sticky"], nil
+	} else if permissions[0] == 'd' && permissions[8] == 'w' {
+		return c.Colors["directory_o+w"], nil
+	} else if permissions[0] == 'd' { // directory
+		return c.Colors["directory"], nil
+	} else if permissions[0] == 'l' && linkOrphan { // orphan link
+		return c.Colors["link_orphan"], nil
+	} else if permissions[0] == 'l' { // symlink
+		return c.Colors["symlink"], nil
+	} else if permissions[3] == 's' { // setuid
+		return c.Colors["executable_suid"], nil
+	} else if permissions[6] == 's' { // setgid
+		return c.Colors["executable_sgid"], nil
+	} else if strings.Contains(permissions, "x") { // executable
+		return c.Colors["executable"], nil
+	} else if isSocket { // socket
+		return c.Colors["socket"], nil
+	} else if isPipe { // pipe
+		return c.Colors["pipe"], nil
+	} else if isBlock { // block
+		return c.Colors["block"], nil
+	} else if isCharacter { // character
+		return c.Colors["character"], nil
+	}
+	return "", nil
+}
@@ -0,0 +1,135 @@
+package listing
+
+import "strings"
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// versionOrder ranks a byte the way dpkg does: digits are handled
+// separately, letters sort before everything else and "~" before even
+// the end of the string.
+func versionOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -1
+	}
+	return int(c) + 256
+}
+
+func verrevcmp(s1, s2 string) int {
+	i, j := 0, 0
+	for i < len(s1) || j < len(s2) {
+		firstDiff := 0
+		for i < len(s1) && !isDigit(s1[i]) || j < len(s2) && !isDigit(s2[j]) {
+			c1 := versionOrder(s1, i)
+			c2 := versionOrder(s2, j)
+			if c1 != c2 {
+				return c1 - c2
+			}
+			i++
+			j++
+		}
+		for i < len(s1) && s1[i] == '0' {
+			i++
+		}
+		for j < len(s2) && s2[j] == '0' {
+			j++
+		}
+		for i < len(s1) && isDigit(s1[i]) && j < len(s2) && isDigit(s2[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(s1[i]) - int(s2[j])
+			}
+			i++
+			j++
+		}
+		if i < len(s1) && isDigit(s1[i]) {
+			return 1
+		}
+		if j < len(s2) && isDigit(s2[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+// filePrefixLen returns the length of s without its trailing run of
+// ".ext" suffixes, so that "foo-1.10.tar.gz" is compared as "foo-1.10"
+// first.
+func filePrefixLen(s string) int {
+	prefixLen := 0
+	for i := 0; i < len(s); {
+		i++
+		prefixLen = i
+		for i+1 < len(s) && s[i] == '.' && (isAlpha(s[i+1]) || s[i+1] == '~') {
+			for i += 2; i < len(s) && (isAlpha(s[i]) || isDigit(s[i]) || s[i] == '~'); i++ {
+			}
+		}
+	}
+	return prefixLen
+}
+
+// FileVersionCompare orders names like GNU filevercmp: runs of digits are
+// compared as numbers, so "file2" < "file10" and "v1.9" < "v1.10". Names
+// that compare equal are ordered bytewise.
+func FileVersionCompare(a, b string) int {
+	result := filevercmp(a, b)
+	if result == 0 {
+		return strings.Compare(a, b)
+	}
+	if result < 0 {
+		return -1
+	}
+	return 1
+}
+
+func filevercmp(a, b string) int {
+	if a == "" || b == "" {
+		return len(a) - len(b)
+	}
+
+	// "." sorts first, then "..", then other hidden files
+	if a[0] == '.' {
+		if b[0] != '.' {
+			return -1
+		}
+		if a == "." || b == "." {
+			return boolToInt(b == ".") - boolToInt(a == ".")
+		}
+		if a == ".." || b == ".." {
+			return boolToInt(b == "..") - boolToInt(a == "..")
+		}
+	} else if b[0] == '.' {
+		return 1
+	}
+
+	prefixA := filePrefixLen(a)
+	prefixB := filePrefixLen(b)
+	result := verrevcmp(a[:prefixA], b[:prefixB])
+	if result != 0 || prefixA == len(a) && prefixB == len(b) {
+		return result
+	}
+	return verrevcmp(a, b)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
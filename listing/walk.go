@@ -0,0 +1,107 @@
+package listing
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// dirNode is a directory of a walk. done is closed once entries, total
+// and err are set.
+type dirNode struct {
+	name    string
+	entries []Entry
+	total   int
+	err     error
+	done    chan struct{}
+}
+
+// WalkFunc is called by Walk for every directory with its entries and the
+// total of their blocks, or with the error that kept it from being read.
+// An error returned by it ends the walk.
+type WalkFunc func(dir string, entries []Entry, total int, err error) error
+
+// walker reads the directories of a walk, at most jobs of them at a time.
+type walker struct {
+	lister Lister
+	slots  chan struct{}
+}
+
+// Walk lists root and every directory below it, like ls -R. fn sees the
+// directories one at a time in the order of a depth first walk, while
+// the next jobs ones to visit are read ahead in parallel, or as many as
+// there are CPUs for 0. Directories read ahead that the subdirectories
+// of a visited one push back stay in memory until their turn, but no
+// more than 2*jobs are held, so the walk of a whole file system does not
+// grow with its size.
+func Walk(lister Lister, root string, jobs int, fn WalkFunc) error {
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	w := &walker{lister, make(chan struct{}, jobs)}
+	// the directories still to visit, the next one on top, and the
+	// number of them that are read or being read
+	stack := []*dirNode{{name: root}}
+	ahead := 0
+	for len(stack) > 0 {
+		top := len(stack) - 1
+		for i := top; i >= 0 && i > top-jobs && (i == top || ahead < 2*jobs); i-- {
+			if stack[i].done == nil {
+				w.read(stack[i])
+				ahead++
+			}
+		}
+		node := stack[top]
+		stack = stack[:top]
+		ahead--
+		<-node.done
+
+		var children []*dirNode
+		if node.err == nil {
+			for _, l := range node.entries {
+				if l.IsDir() && l.Name != "." && l.Name != ".." {
+					children = append(children, &dirNode{name: SubdirName(node.name, l.Name)})
+				}
+			}
+		}
+		if err := fn(node.name, node.entries, node.total, node.err); err != nil {
+			return err
+		}
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, children[i])
+		}
+	}
+	return nil
+}
+
+// ParseJobs validates a --jobs value.
+func ParseJobs(value string) (int, error) {
+	jobs, err := strconv.Atoi(value)
+	if err != nil || jobs < 1 {
+		return 0, fmt.Errorf("invalid number of jobs: '%s'", value)
+	}
+	return jobs, nil
+}
+
+// read starts reading the directory of node once a slot is free.
+func (w *walker) read(node *dirNode) {
+	node.done = make(chan struct{})
+	go func() {
+		defer close(node.done)
+		w.slots <- struct{}{}
+		node.entries, node.total, node.err = w.lister.ReadDir(node.name)
+		<-w.slots
+	}()
+}
+
+// SubdirName joins a directory and an entry in it without doubling the
+// slash of "dir/" or "/".
+func SubdirName(dir, name string) string {
+	for len(dir) > 1 && dir[len(dir)-1] == '/' {
+		dir = dir[:len(dir)-1]
+	}
+	if dir == "/" {
+		return dir + name
+	}
+	return dir + "/" + name
+}
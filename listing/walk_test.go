@@ -0,0 +1,187 @@
+package listing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// aheadLister counts the directories read before fn visits them, and the
+// reads running at once.
+type aheadLister struct {
+	Lister
+	lock       sync.Mutex
+	read       int
+	visited    int
+	maxAhead   int
+	reading    int
+	maxReading int
+}
+
+func (l *aheadLister) ReadDir(name string) ([]Entry, int, error) {
+	l.lock.Lock()
+	l.read++
+	if ahead := l.read - l.visited; ahead > l.maxAhead {
+		l.maxAhead = ahead
+	}
+	if l.reading++; l.reading > l.maxReading {
+		l.maxReading = l.reading
+	}
+	l.lock.Unlock()
+	defer func() {
+		l.lock.Lock()
+		l.reading--
+		l.lock.Unlock()
+	}()
+	return l.Lister.ReadDir(name)
+}
+
+func (l *aheadLister) visit() {
+	l.lock.Lock()
+	l.visited++
+	l.lock.Unlock()
+}
+
+// makeTree creates width directories on each of depth levels below dir,
+// with files regular files in each directory.
+func makeTree(dir string, width, depth, files int) error {
+	for i := 0; i < files; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d", i)), nil, 0644); err != nil {
+			return err
+		}
+	}
+	if depth == 0 {
+		return nil
+	}
+	for i := 0; i < width; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("dir%d", i))
+		if err := os.Mkdir(sub, 0755); err != nil {
+			return err
+		}
+		if err := makeTree(sub, width, depth-1, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkOrder lists the directories below dir depth first, one at a time.
+func walkOrder(lister Lister, dir string) []string {
+	order := []string{dir}
+	entries, _, _ := lister.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir() {
+			order = append(order, walkOrder(lister, SubdirName(dir, e.Name))...)
+		}
+	}
+	return order
+}
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	if err := makeTree(dir, 4, 3, 2); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	want := walkOrder(NewLister(&config), dir)
+
+	for _, jobs := range []int{1, 3, 8} {
+		lister := &aheadLister{Lister: NewLister(&config)}
+		var got []string
+		err := Walk(lister, dir, jobs, func(name string, entries []Entry, total int, err error) error {
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+			lister.visit()
+			got = append(got, name)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("jobs=%d: visited\n%s\nwant\n%s", jobs, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+		// the directory being visited and the ones read ahead
+		if lister.maxAhead > 2*jobs+1 {
+			t.Errorf("jobs=%d: held %d directories", jobs, lister.maxAhead)
+		}
+		if lister.maxReading > jobs {
+			t.Errorf("jobs=%d: read %d directories at once", jobs, lister.maxReading)
+		}
+	}
+}
+
+func TestWalkStops(t *testing.T) {
+	dir := t.TempDir()
+	if err := makeTree(dir, 3, 3, 0); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	stop := fmt.Errorf("stop")
+	visited := 0
+	err := Walk(NewLister(&config), dir, 2, func(string, []Entry, int, error) error {
+		if visited++; visited == 5 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || visited != 5 {
+		t.Errorf("Walk = %v after %d directories, want stop after 5", err, visited)
+	}
+}
+
+var benchTree struct {
+	once sync.Once
+	dir  string
+	err  error
+}
+
+// benchTreeDir returns a tree of 1111 directories with 90 files each,
+// about 100,000 files, created once for all the benchmarks.
+func benchTreeDir(b *testing.B) string {
+	benchTree.once.Do(func() {
+		benchTree.dir, benchTree.err = os.MkdirTemp("", "walk")
+		if benchTree.err == nil {
+			benchTree.err = makeTree(benchTree.dir, 10, 3, 90)
+		}
+	})
+	if benchTree.err != nil {
+		b.Fatal(benchTree.err)
+	}
+	return benchTree.dir
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchTree.dir != "" {
+		os.RemoveAll(benchTree.dir)
+	}
+	os.Exit(code)
+}
+
+func BenchmarkWalk(b *testing.B) {
+	dir := benchTreeDir(b)
+	config := DefaultConfig()
+	config.Numeric = true
+	for _, jobs := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				files := 0
+				err := Walk(NewLister(&config), dir, jobs, func(name string, entries []Entry, total int, err error) error {
+					files += len(entries)
+					return err
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if files != 1111*90+1110 {
+					b.Fatalf("walked %d entries", files)
+				}
+			}
+		})
+	}
+}
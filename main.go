@@ -4,85 +4,54 @@ import "C"
 
 import (
 	"fmt"
+	"math"
 	"os"
-	"strings"
-)
-
-type List struct {
-	permissions string
-	hardLinks   string
-	owner       string
-	group       string
-	size        string
-	epochNano   int64
-	month       string
-	day         string
-	time        string
-	name        string
-	linkName    string
-	linkColor   string
-	major       string
-	minor       string
-	linkOrphan  bool
-	isSocket    bool
-	isPipe      bool
-	isBlock     bool
-	isCharacter bool
-}
 
-type Dir struct {
-	name      string
-	epochNano int64
-	size      int64
-}
+	"github.com/tadilbek11kz/ls-clone/listing"
+)
 
+// Options are the settings of the listing and those only the command
+// line has: operands to walk, layout precedence and environment defaults.
 type Options struct {
-	all         bool
-	long        bool
-	human       bool
-	one         bool
-	dir         bool
-	color       bool
-	sortReverse bool
-	sortTime    bool
-	sortSize    bool
-	help        bool
-	dirsFirst   bool
-	recursive   bool
+	listing.Config
+	dir            bool
+	tree           bool
+	level          int
+	hideControlSet bool
+	layoutSet      bool
+	help           bool
+	recursive      bool
+	jobs           int
+	tabSizeSet     bool
+	timeStyle      string
+	timeShortFlag  bool
 }
 
-type FileInfoPath struct {
-	path string
-	info os.FileInfo
+var (
+	options   Options
+	lister    listing.Lister
+	formatter listing.Formatter
+)
+
+// AccessError describes a failed Stat of an operand.
+func AccessError(name string, err error) string {
+	if os.IsNotExist(err) {
+		return fmt.Sprintf("cannot access %s: No such file or directory", QuoteOperand(name))
+	}
+	if _, ok := err.(*os.PathError); ok {
+		return fmt.Sprintf("cannot access %s: %s", QuoteOperand(name), ErrorText(err))
+	}
+	return err.Error()
 }
 
-func ls(lsOutput *[]string, files []string) error {
-	var output []string
-	var dirsList []List
-	var filesList []List
-	var size int
+func ls(files []string) error {
+	var dirsList []listing.Entry
+	var filesList []listing.Entry
 
 	if len(files) == 0 {
-		currentDir, err := os.Lstat(".")
-		if err != nil && os.IsPermission(err) {
-			lsOutputLen := len(*lsOutput)
-			if lsOutputLen == 0 {
-				*lsOutput = append(*lsOutput, fmt.Sprintf("ls: cannot open directory .: Permission denied"))
-			} else {
-				(*lsOutput)[lsOutputLen-1] = fmt.Sprintf("%v\nls: cannot open directory .: Permission denied", (*lsOutput)[lsOutputLen-1])
-			}
-			return err
-		} else if err != nil {
-			return err
-		}
-		dirList, _, err := CreateList(".", FileInfoPath{".", currentDir})
+		dirList, err := lister.Stat(".")
 		if err != nil && os.IsPermission(err) {
-			lsOutputLen := len(*lsOutput)
-			if lsOutputLen == 0 {
-				*lsOutput = append(*lsOutput, fmt.Sprintf("ls: cannot open directory .: Permission denied"))
-			} else {
-				(*lsOutput)[lsOutputLen-1] = fmt.Sprintf("%v\nls: cannot open directory .: Permission denied", (*lsOutput)[lsOutputLen-1])
-			}
+			PrintError("cannot open directory '.': Permission denied")
 			return err
 		} else if err != nil {
 			return err
@@ -95,204 +64,162 @@ func ls(lsOutput *[]string, files []string) error {
 	}
 
 	for _, f := range files {
-		info, err := os.Stat(f)
-		if err != nil && os.IsNotExist(err) {
-			return fmt.Errorf("cannot access %s: no such file or directory", f)
-		} else if err != nil {
-			lsOutputLen := len(*lsOutput)
-			if lsOutputLen == 0 {
-				*lsOutput = append(*lsOutput, "ls: "+err.Error())
-			} else {
-				(*lsOutput)[lsOutputLen-1] = fmt.Sprintf("%v\n%s", (*lsOutput)[lsOutputLen-1], err.Error())
-			}
-			continue
-		}
-		path := f
-		infoPath := f
-		if !info.IsDir() {
-			splitedPath := strings.Split(path, "/")
-			path = strings.Join(splitedPath[:len(splitedPath)-1], "/")
-		}
-		fileList, blocksize, err := CreateList(path, FileInfoPath{infoPath, info})
+		fileList, err := lister.Stat(f)
 		if err != nil {
-			lsOutputLen := len(*lsOutput)
-			if lsOutputLen == 0 {
-				*lsOutput = append(*lsOutput, "ls: "+err.Error())
-			} else {
-				(*lsOutput)[lsOutputLen-1] = fmt.Sprintf("%v\n%s", (*lsOutput)[lsOutputLen-1], err.Error())
-			}
+			PrintError(AccessError(f, err))
+			SetExitStatus(exitTrouble)
 			continue
 		}
 
-		if options.dir {
+		if options.dir || !fileList.IsDir() {
 			filesList = append(filesList, fileList)
 		} else {
-			if info.IsDir() {
-				dirsList = append(dirsList, fileList)
-			} else {
-				filesList = append(filesList, fileList)
-				size += blocksize
-			}
+			dirsList = append(dirsList, fileList)
 		}
 	}
 
-	filesNum := len(filesList)
-	dirsNum := len(dirsList)
-	SortList(filesList)
-	SortList(dirsList)
+	options.Sort(filesList)
+	options.Sort(dirsList)
 
-	if filesNum > 0 {
-		toWrite := WriteListToOuptut(filesList, terminalWidth)
-		if len(files) > 1 {
-			toWrite += "\n"
-		}
-		if len(toWrite) > 0 {
-			output = append(output, toWrite)
-		}
-		size = 0
+	if len(filesList) > 0 {
+		WriteBlock(formatter.Format(filesList))
 	}
 
-	if (filesNum > 0 && dirsNum > 0) || (dirsNum > 1) {
-		for index, d := range dirsList {
-			if index == 0 {
-				output = append(output, fmt.Sprintf("%v:", d.name))
-			} else {
-				output = append(output, fmt.Sprintf("\n%v:", d.name))
-			}
-
-			listings, blocksize, err := ListDirFiles(d)
-			size += blocksize
-			if options.long {
-				output = append(output, fmt.Sprintf("total %v", size))
-			}
-			size = 0
-			if err != nil {
-				lsOutputLen := len(*lsOutput)
-				if lsOutputLen == 0 {
-					*lsOutput = append(*lsOutput, "ls: "+err.Error())
-				} else {
-					(*lsOutput)[lsOutputLen-1] = fmt.Sprintf("%v\n%s", (*lsOutput)[lsOutputLen-1], err.Error())
-				}
-				continue
-			}
-
-			if options.dirsFirst {
-				listings = SortDirsFirst(listings)
-			}
+	// directories get a header when there is more than one block
+	header := len(filesList) > 0 || len(dirsList) > 1 || options.recursive
+	for _, d := range dirsList {
+		listings, size, err := lister.ReadDir(d.Path)
+		if err != nil {
+			PrintError(fmt.Sprintf("cannot open directory %s: %s", QuoteOperand(d.Path), ErrorText(err)))
+			SetExitStatus(exitTrouble)
+			continue
+		}
+		WriteBlock(formatter.FormatDir(d.Path, listings, size, header))
+	}
+	return nil
+}
 
-			if len(listings) > 0 {
-				toWrite := WriteListToOuptut(listings, terminalWidth)
-				if len(toWrite) > 0 {
-					output = append(output, toWrite)
-				}
+// recursion lists each operand and everything below it, depth first. The
+// directories are read in parallel by listing.Walk while the blocks are
+// written in the order of a sequential walk.
+func recursion(files []string) error {
+	if len(files) == 0 {
+		files = []string{"."}
+	}
+	for _, f := range files {
+		root, err := lister.Stat(f)
+		if err != nil || !root.IsDir() || options.dir {
+			// ls reports the error or lists the file
+			if err := ls([]string{f}); err != nil && !os.IsPermission(err) {
+				return err
 			}
+			continue
 		}
-	} else if dirsNum == 1 {
-		for _, d := range dirsList {
-
-			listings, blocksize, err := ListDirFiles(d)
-			size += blocksize
+		err = listing.Walk(lister, f, options.jobs, func(dir string, entries []listing.Entry, total int, err error) error {
 			if err != nil {
-				lsOutputLen := len(*lsOutput)
-				if lsOutputLen == 0 {
-					*lsOutput = append(*lsOutput, "ls: "+err.Error())
-				} else {
-					(*lsOutput)[lsOutputLen-1] = fmt.Sprintf("%v\n%s", (*lsOutput)[lsOutputLen-1], err.Error())
+				// an operand that cannot be read is more serious than a
+				// subdirectory
+				status := exitMinor
+				if dir == f {
+					status = exitTrouble
 				}
-				continue
-			}
-			if options.recursive {
-				output = append(output, fmt.Sprintf("%v:", d.name))
-			}
-			if options.dirsFirst {
-				listings = SortDirsFirst(listings)
-			}
-			if options.long {
-				output = append(output, fmt.Sprintf("total %v", size))
-			}
-			size = 0
-			toWrite := WriteListToOuptut(listings, terminalWidth)
-			if len(toWrite) > 0 {
-				output = append(output, toWrite)
+				PrintError(fmt.Sprintf("cannot open directory %s: %s", QuoteOperand(dir), ErrorText(err)))
+				SetExitStatus(status)
+				return nil
 			}
+			WriteBlock(formatter.FormatDir(dir, entries, total, true))
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
-
-	//
-	// list the files now if --dirs-first
-	//
-
-	if output != nil {
-		*lsOutput = append(*lsOutput, strings.Join(output, "\n"))
-	}
 	return nil
 }
 
-func recursion(output *[]string, files []string) error {
-	var list []os.FileInfo
-	for _, dir := range files {
-		err := ls(output, []string{dir})
-		if err != nil && !os.IsPermission(err) {
-			return err
-		}
-		path := dir
-		var dirs []List
-		fi, err := os.Stat(dir)
-		if err != nil && !os.IsPermission(err) {
-			return err
-		}
-		if fi.Mode().IsDir() {
-			list, err = ReadDir(dir)
+// tree lists every operand as the root of a tree of its contents, laid
+// out with -l or one entry per line. The directories that could not be
+// read are reported after the tree.
+func tree(files []string) error {
+	if len(files) == 0 {
+		files = []string{"."}
+	}
+	for _, f := range files {
+		root, err := lister.Stat(f)
+		if err != nil {
+			PrintError(AccessError(f, err))
+			SetExitStatus(exitTrouble)
+			continue
 		}
-		if err != nil && !os.IsPermission(err) {
-			return err
+
+		list := []listing.Entry{root}
+		var errs []error
+		if !options.dir {
+			list, errs = listing.Tree(lister, root, options.level, options.DirsFirst)
 		}
-		for _, dirInfo := range list {
-			var dirTemp List
-			invisibleCheck := string(dirInfo.Name()[0]) != "."
-			if options.all {
-				invisibleCheck = true
-			}
-			if dirInfo.IsDir() && invisibleCheck {
-				for path[len(path)-1] == '/' {
-					path = path[:len(path)-1]
-				}
-				dirTemp.name = path + "/" + dirInfo.Name()
-				dirTemp.size = fmt.Sprintf("%d", dirInfo.Size())
-				dirTemp.epochNano = dirInfo.ModTime().UnixNano()
-				dirs = append(dirs, dirTemp)
+		WriteBlock(formatter.Format(list))
+		for _, e := range errs {
+			if dirErr, ok := e.(*listing.DirError); ok {
+				PrintError(fmt.Sprintf("cannot open directory %s: %s", QuoteOperand(dirErr.Dir), ErrorText(dirErr.Err)))
+			} else {
+				PrintError(e.Error())
 			}
-		}
-		if len(dirs) > 0 {
-			sortedlist := BubbleSort(dirs, options.sortReverse)
-			err = recursion(output, sortedlist)
-		}
-		if err != nil && !os.IsPermission(err) {
-			return err
+			SetExitStatus(exitMinor)
 		}
 	}
-
 	return nil
 }
 
 func main() {
 	var err error
 	args := os.Args[1:]
-	var flags []string
 	var files []string
-	var output string
-	var result []string
-	for _, arg := range args {
-		if len(arg) > 1 && []rune(arg)[0] == '-' {
-			// add to the options list
-			flags = append(flags, arg)
-		} else {
-			// add to the files/directories list
-			files = append(files, arg)
+
+	options, files, err = ParseOptions(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ls: %v\n", err.Error())
+		if _, ok := err.(*UsageError); ok {
+			fmt.Fprintln(os.Stderr, "Try 'ls --help' for more information.")
 		}
+		os.Exit(2)
 	}
 
-	options = ParseOptions(flags)
+	if options.timeShortFlag && !options.Long {
+		// -u and -c sort by their time unless it is shown with -l
+		options.SortTime = true
+	}
+	if options.QuotingStyle == "" {
+		style := os.Getenv("QUOTING_STYLE")
+		if listing.IsQuotingStyle(style) {
+			options.QuotingStyle = style
+		} else if style != "" {
+			fmt.Fprintf(os.Stderr, "ls: ignoring invalid value of environment variable QUOTING_STYLE: '%s'\n", style)
+		}
+	}
+	if options.QuotingStyle == "" {
+		// names pasted from a terminal should work in a shell
+		if IsTerminal(os.Stdout.Fd()) {
+			options.QuotingStyle = "shell-escape"
+		} else {
+			options.QuotingStyle = "literal"
+		}
+	}
+	if !options.hideControlSet {
+		options.HideControl = IsTerminal(os.Stdout.Fd())
+	}
+	options.ByteCollation = IsByteCollation()
+	options.Collation = GetLocale("LC_COLLATE")
+	if options.timeStyle == "" {
+		options.timeStyle = os.Getenv("TIME_STYLE")
+	}
+	if options.timeStyle == "" {
+		options.timeStyle = "locale"
+	}
+	options.TimeFormatOld, options.TimeFormatRecent, err = ParseTimeStyle(options.timeStyle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ls: %v\n", err.Error())
+		os.Exit(2)
+	}
 
 	if options.help {
 		help := "usage:  ls [OPTIONS] [FILES]\n\n" +
@@ -301,38 +228,141 @@ func main() {
 			"    --help        display usage information\n" +
 			"    --nocolor     remove color formatting\n" +
 			"    -1            one entry per line\n" +
+			"    -C            list entries by columns\n" +
+			"    -x            list entries by lines instead of by columns\n" +
+			"    -m            fill width with a comma separated list\n" +
 			"    -a            include entries starting with '.'\n" +
+			"    -b            print C-style escapes for nongraphic characters\n" +
 			"    -d            list directories like files\n" +
+			"    --tree        list subdirectories as an indented tree\n" +
+			"    -L, --level=N with --tree, descend at most N directories\n" +
+			"    --jobs=N      with -R, read up to N directories at once;\n" +
+			"                  the default is the number of CPUs\n" +
+			"    -F, --classify[=WHEN]\n" +
+			"                  append indicator (one of */=>@|) to entries;\n" +
+			"                  WHEN can be 'always', 'auto' or 'never'\n" +
+			"    --file-type   likewise, except do not append '*'\n" +
+			"    --indicator-style=WORD\n" +
+			"                  none, slash (-p), file-type or classify (-F)\n" +
 			"    -h            list sizes with human-readable units\n" +
+			"    --hyperlink[=WHEN]\n" +
+			"                  hyperlink file names; WHEN can be 'always',\n" +
+			"                  'auto' or 'never'\n" +
+			"    -i            print the inode number of each file\n" +
+			"    --icons[=WHEN]\n" +
+			"                  show a Nerd Font icon before each name; WHEN\n" +
+			"                  can be 'always', 'auto' or 'never'. Icons are\n" +
+			"                  read from ~/.config/ls/icons as KEY=GLYPH lines\n" +
+			"                  with a file name, *.ext or LS_COLORS type code\n" +
 			"    -l            long listing\n" +
+			"    --git         with -l, show the git status of each entry:\n" +
+			"                  staged and work tree changes (M modified,\n" +
+			"                  A added, D deleted, T type, U conflict, -\n" +
+			"                  none), ?? untracked or !! ignored\n" +
+			"    -p            append / indicator to directories\n" +
+			"    -q            print ? instead of nongraphic characters\n" +
+			"    -N            print entry names without quoting\n" +
+			"    -Q            enclose entry names in double quotes\n" +
+			"    --show-control-chars\n" +
+			"                  show nongraphic characters as-is\n" +
+			"    --quoting-style=WORD\n" +
+			"                  literal, shell, shell-always, shell-escape,\n" +
+			"                  shell-escape-always, c or escape; overrides\n" +
+			"                  QUOTING_STYLE\n" +
+			"    -n            like -l, but list numeric user and group IDs\n" +
+			"    -g            like -l, but do not list owner\n" +
+			"    -o            like -l, but do not list group\n" +
+			"    -G            in a long listing, don't print group names\n" +
+			"    --author      with -l, print the author of each file\n" +
+			"    --full-time   like -l --time-style=full-iso\n" +
+			"    --time-style=STYLE\n" +
+			"                  full-iso, long-iso, iso, locale or +FORMAT;\n" +
+			"                  FORMAT is interpreted like in strftime and may\n" +
+			"                  hold a second format for recent files after a\n" +
+			"                  newline; TIME_STYLE sets the default\n" +
 			"    -r            reverse any sorting\n" +
+			"    -s            print the allocated size of each file in blocks\n" +
 			"    -t            sort entries by modify time\n" +
-			"    -S            sort entries by size\n"
+			"    -u            show and sort by access time\n" +
+			"    -c            show and sort by status change time\n" +
+			"    --time=WORD   atime, ctime, mtime or birth, the time to\n" +
+			"                  show with -l and to sort by with -t\n" +
+			"    -S            sort entries by size\n" +
+			"    -X            sort entries by extension\n" +
+			"    -v            natural sort of version numbers within names\n" +
+			"    -U            do not sort; list entries in directory order,\n" +
+			"                  except that -a puts . and .. first\n" +
+			"    --sort=KEY[,KEY...]\n" +
+			"                  sort by name, size, time, mtime, atime, ctime,\n" +
+			"                  ext, version, owner, group, inode, nlink or\n" +
+			"                  type, later keys breaking ties; keys sort in\n" +
+			"                  ascending order, a '-' before a key reverses\n" +
+			"                  it, 'none' is like -U. Names sort in an\n" +
+			"                  approximate Unicode order with the letters of\n" +
+			"                  the LC_COLLATE language, such as Swedish å, ä\n" +
+			"                  and ö after z, or by bytes when LC_COLLATE is\n" +
+			"                  C or POSIX\n" +
+			"    -f            like -aU, and disable -l, -s and color\n" +
+			"    -w, --width=N set output width to N, 0 means no limit\n" +
+			"    -T, --tabsize=N\n" +
+			"                  assume tab stops at each N instead of 8\n" +
+			"    --json        print one JSON record per listed directory\n" +
+			"    --format=WORD across -x, commas -m, horizontal -x, long -l,\n" +
+			"                  single-column -1, verbose -l, vertical -C,\n" +
+			"                  or csv and tsv rows with the -l columns\n"
 		fmt.Println(help)
 		return
 	}
 
-	if options.color {
-		colorsMap = ParseColors()
+	if options.Color {
+		options.Colors = listing.ParseColors(os.Getenv("LS_COLORS"))
 	}
-	if !options.recursive {
-		var tmp []string
-		err = ls(&tmp, files)
-		output = strings.Join(tmp, "\n")
-	} else {
-		if len(files) == 0 {
-			files = append(files, ".")
+	if options.Hyperlink {
+		options.Hostname, _ = os.Hostname()
+	}
+	if options.Icons {
+		var warnings []error
+		options.UserIcons, warnings, err = listing.LoadIcons(listing.IconsFile())
+		for _, warning := range warnings {
+			PrintError(warning.Error())
+		}
+		if err != nil {
+			PrintError(err.Error())
 		}
-		err = recursion(&result, files)
 	}
-
-	if err != nil {
-		fmt.Printf("ls: %v\n", err.Error())
-		os.Exit(1)
+	options.Width = GetTerminalWidth()
+	options.TabSize = GetTabSize()
+	if options.Width == math.MaxInt32 {
+		// a single unlimited line is never aligned with tabs
+		options.TabSize = 0
+	}
+	if !IsTerminal(os.Stdout.Fd()) && !options.layoutSet {
+		// like GNU ls, print one entry per line when piped
+		options.One = true
+	}
+	if options.tree && !options.Long && !options.IsRecordFormat() {
+		// a tree has one entry per line
+		SetLayout(&options, "single-column")
 	}
-	if options.recursive {
-		fmt.Println(strings.Join(result, "\n\n"))
+	fileLister := listing.NewLister(&options.Config)
+	textFormatter := listing.NewFormatter(&options.Config)
+	textFormatter.DirURL = fileLister.URL
+	lister, formatter = fileLister, textFormatter
+	WriteOutput(formatter.Header())
+	if options.tree && !options.IsRecordFormat() {
+		err = tree(files)
+	} else if !options.recursive {
+		err = ls(files)
 	} else {
-		fmt.Println(output)
+		err = recursion(files)
+	}
+	PrintWarnings()
+	if err != nil && !os.IsPermission(err) {
+		PrintError(err.Error())
+	}
+	if err != nil {
+		SetExitStatus(exitTrouble)
 	}
+	FlushOutput()
+	os.Exit(exitStatus)
 }
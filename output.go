@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/tadilbek11kz/ls-clone/listing"
+)
+
+// exit status of a process killed by SIGPIPE, used when the reader of
+// the output goes away while SIGPIPE is ignored
+const exitBrokenPipe = 128 + int(syscall.SIGPIPE)
+
+// exit statuses of GNU ls: minor problems like an unreadable
+// subdirectory, and serious trouble like a missing operand
+const (
+	exitMinor   = 1
+	exitTrouble = 2
+)
+
+var (
+	stdout        = bufio.NewWriterSize(os.Stdout, 64*1024)
+	blocksWritten = 0
+	exitStatus    = 0
+)
+
+// WriteFailed ends the program after a write to stdout failed. A closed
+// pipe, as with "ls -R / | head", is not an error worth reporting.
+func WriteFailed(err error) {
+	if errors.Is(err, syscall.EPIPE) {
+		os.Exit(exitBrokenPipe)
+	}
+	fmt.Fprintf(os.Stderr, "ls: write error: %v\n", err)
+	os.Exit(2)
+}
+
+func WriteOutput(s string) {
+	if _, err := stdout.WriteString(s); err != nil {
+		WriteFailed(err)
+	}
+}
+
+func FlushOutput() {
+	if err := stdout.Flush(); err != nil {
+		WriteFailed(err)
+	}
+}
+
+// WriteBlock writes the listing of one operand or directory as soon as it
+// is ready, after the warnings met while reading it. Blocks are separated
+// by a blank line, except for the records of --json and --format=csv.
+func WriteBlock(block string) {
+	PrintWarnings()
+	if block == "" {
+		return
+	}
+	if blocksWritten > 0 && !options.IsRecordFormat() {
+		WriteOutput("\n")
+	}
+	WriteOutput(block + "\n")
+	blocksWritten++
+	FlushOutput()
+}
+
+// PrintError reports a problem with one file on stderr, after the output
+// that comes before it.
+func PrintError(message string) {
+	FlushOutput()
+	fmt.Fprintf(os.Stderr, "ls: %s\n", message)
+}
+
+// PrintWarnings prints the problems the lister met without failing, such
+// as an unreadable git repository.
+func PrintWarnings() {
+	if l, ok := lister.(interface{ Warnings() []error }); ok {
+		for _, err := range l.Warnings() {
+			PrintError(err.Error())
+		}
+	}
+}
+
+// QuoteOperand quotes a file name in an error message like GNU ls does:
+// always in shell quotes, whatever the quoting style of the listing.
+func QuoteOperand(name string) string {
+	return listing.ShellQuote(name, true, true)
+}
+
+// ErrorText is the message of err without the path around it, worded like
+// strerror(3) for system errors.
+func ErrorText(err error) string {
+	err = listing.UnwrapError(err)
+	text := err.Error()
+	if _, ok := err.(syscall.Errno); ok && text != "" {
+		return strings.ToUpper(text[:1]) + text[1:]
+	}
+	return text
+}
+
+// SetExitStatus keeps the most serious status seen so far.
+func SetExitStatus(status int) {
+	if status > exitStatus {
+		exitStatus = status
+	}
+}
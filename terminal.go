@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"github.com/tadilbek11kz/ls-clone/listing"
+)
+
+const defaultTerminalWidth = 80
+
+func errnoErr(e syscall.Errno) error {
+	switch e {
+	case 0:
+		return nil
+	case syscall.Errno(0x23):
+		return syscall.EAGAIN
+	case syscall.Errno(0x16):
+		return syscall.EINVAL
+	case syscall.Errno(0x2):
+		return syscall.ENOENT
+	}
+	return e
+}
+
+type winsize struct {
+	row    uint16
+	col    uint16
+	xpixel uint16
+	ypixel uint16
+}
+
+func GetWinsize(fd uintptr) (winsize, error) {
+	var ws winsize
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL,
+		fd,
+		uintptr(syscall.TIOCGWINSZ),
+		uintptr(unsafe.Pointer(&ws)))
+	return ws, errnoErr(e)
+}
+
+func IsTerminal(fd uintptr) bool {
+	var termios syscall.Termios
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL,
+		fd,
+		uintptr(syscall.TCGETS),
+		uintptr(unsafe.Pointer(&termios)))
+	return e == 0
+}
+
+// ParseWidth validates a -w/--width or COLUMNS value. A width of 0 means
+// no limit, like in GNU ls.
+func ParseWidth(value string) (int, error) {
+	width, err := strconv.Atoi(value)
+	if err != nil || width < 0 {
+		return 0, fmt.Errorf("invalid line width: '%s'", value)
+	}
+	if width == 0 {
+		return math.MaxInt32, nil
+	}
+	return width, nil
+}
+
+// GetTerminalWidth picks the line width for column output: -w/--width
+// wins, then the size of the terminal on stdout, then $COLUMNS.
+func GetTerminalWidth() int {
+	if options.Width > 0 {
+		return options.Width
+	}
+	if ws, err := GetWinsize(os.Stdout.Fd()); err == nil && ws.col > 0 {
+		return int(ws.col)
+	}
+	if columns := os.Getenv("COLUMNS"); columns != "" {
+		if width, err := ParseWidth(columns); err == nil && width > 0 {
+			return width
+		}
+	}
+	return defaultTerminalWidth
+}
+
+// GetTabSize returns -T/--tabsize, then $TABSIZE, then 8.
+func GetTabSize() int {
+	if options.tabSizeSet {
+		return options.TabSize
+	}
+	if value := os.Getenv("TABSIZE"); value != "" {
+		size, err := listing.ParseTabSize(value)
+		if err == nil {
+			return size
+		}
+		fmt.Fprintf(os.Stderr, "ls: ignoring invalid tab size in environment variable TABSIZE: '%s'\n", value)
+	}
+	return listing.DefaultTabSize
+}
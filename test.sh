@@ -4,8 +4,8 @@ ls > ../test.txt
 diff ../test.txt ../test2.txt
 
 echo "Test#2: "
-ls utils.go > ../test.txt
-./my-ls-1 -1 utils.go --nocolor > ../test2.txt
+ls main.go > ../test.txt
+./my-ls-1 -1 main.go --nocolor > ../test2.txt
 diff ../test.txt ../test2.txt
 
 echo "Test#3: "
@@ -19,8 +19,8 @@ ls -l > ../test.txt
 diff ../test.txt ../test2.txt
 
 echo "Test#5: "
-ls -l utils.go > ../test.txt
-./my-ls-1 -l utils.go --nocolor > ../test2.txt
+ls -l main.go > ../test.txt
+./my-ls-1 -l main.go --nocolor > ../test2.txt
 diff ../test.txt ../test2.txt
 
 echo "Test#6: "
@@ -69,8 +69,8 @@ ls -lRr ../ascii-art > ../test.txt
 diff ../test.txt ../test2.txt
 
 echo "Test#15: "
-ls -l ../ascii-art -a utils.go > ../test.txt
-./my-ls-1 -l ../ascii-art -a utils.go --nocolor > ../test2.txt
+ls -l ../ascii-art -a main.go > ../test.txt
+./my-ls-1 -l ../ascii-art -a main.go --nocolor > ../test2.txt
 diff ../test.txt ../test2.txt
 
 echo "Test#16: "
@@ -113,3 +113,23 @@ ls "test" > ../test.txt
 ./my-ls-1 "test" -1 --nocolor > ../test2.txt
 diff ../test.txt ../test2.txt
 
+echo "Test#24: "
+ls -C -w 80 /usr/bin > ../test.txt
+./my-ls-1 -C -w 80 /usr/bin --nocolor > ../test2.txt
+diff ../test.txt ../test2.txt
+
+echo "Test#25: "
+ls -x -w 120 -T 4 /usr/bin > ../test.txt
+./my-ls-1 -x -w 120 -T 4 /usr/bin --nocolor > ../test2.txt
+diff ../test.txt ../test2.txt
+
+
+echo "Test#26: "
+ls -l1 > ../test.txt
+./my-ls-1 -l1 --nocolor > ../test2.txt
+diff ../test.txt ../test2.txt
+
+echo "Test#27: "
+ls -la1 dir > ../test.txt
+./my-ls-1 -la1 dir --nocolor > ../test2.txt
+diff ../test.txt ../test2.txt
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/tadilbek11kz/ls-clone/listing"
+)
+
+var timeFields = []string{"atime", "access", "use", "ctime", "status", "mtime", "modification", "birth", "creation"}
+
+var timeStyles = []string{"full-iso", "long-iso", "iso", "locale", "+FORMAT"}
+
+// ParseTimeStyle returns the strftime formats for files older than six
+// months and for recent ones. "+FORMAT" may hold two formats separated by
+// a newline, the first for old files and the second for recent ones.
+func ParseTimeStyle(style string) (string, string, error) {
+	if strings.HasPrefix(style, "posix-") {
+		if IsPosixLocale("LC_TIME") {
+			return listing.LocaleTimeOld, listing.LocaleTimeRecent, nil
+		}
+		style = strings.TrimPrefix(style, "posix-")
+	}
+	if strings.HasPrefix(style, "+") {
+		formats := strings.SplitN(style[1:], "\n", 2)
+		if len(formats) == 1 {
+			return formats[0], formats[0], nil
+		}
+		return formats[0], formats[1], nil
+	}
+	switch style {
+	case "full-iso":
+		return "%Y-%m-%d %H:%M:%S.%N %z", "%Y-%m-%d %H:%M:%S.%N %z", nil
+	case "long-iso":
+		return "%Y-%m-%d %H:%M", "%Y-%m-%d %H:%M", nil
+	case "iso":
+		return "%Y-%m-%d ", "%m-%d %H:%M", nil
+	case "locale":
+		return listing.LocaleTimeOld, listing.LocaleTimeRecent, nil
+	}
+	return "", "", InvalidArgument(style, "--time-style", timeStyles)
+}
+
+func IsPosixLocale(category string) bool {
+	locale := GetLocale(category)
+	return locale == "C" || locale == "POSIX"
+}
+
+// GetLocale returns the locale that applies to a category, following the
+// LC_ALL > LC_xxx > LANG precedence of setlocale(3).
+func GetLocale(category string) string {
+	for _, name := range []string{"LC_ALL", category, "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return "C"
+}
+
+// IsByteCollation reports whether LC_COLLATE is the C or POSIX locale,
+// where names compare as bytes. Any other locale gets the approximate
+// Unicode order of listing.CollationKey, tailored for its language.
+func IsByteCollation() bool {
+	locale := GetLocale("LC_COLLATE")
+	return locale == "C" || locale == "POSIX" || strings.HasPrefix(locale, "C.")
+}
+
+func ParseTimeField(value string) (string, error) {
+	switch value {
+	case "atime", "access", "use":
+		return "atime", nil
+	case "ctime", "status":
+		return "ctime", nil
+	case "mtime", "modification":
+		return "mtime", nil
+	case "birth", "creation":
+		return "birth", nil
+	}
+	return "", InvalidArgument(value, "--time", timeFields)
+}